@@ -2,12 +2,13 @@ package devnet
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	starknetutils "github.com/NethermindEth/starknet.go/utils"
 	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/ocr2"
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/rs/zerolog/log"
@@ -86,7 +87,7 @@ func (devnet *StarknetDevnetClient) AutoLoadState(client *ocr2.Client, ocrAddres
 				log.Debug().Msg("Checking for devnet OCR contract errors")
 
 				_, err := client.LatestTransmissionDetails(devnet.ctx, addr)
-				if err != nil && strings.Contains(err.Error(), "is not deployed") {
+				if errors.Is(err, starknet.ErrContractNotDeployed) {
 					_, err = devnet.client.R().SetBody(map[string]any{
 						"path": devnet.dumpPath,
 					}).Post("/load")
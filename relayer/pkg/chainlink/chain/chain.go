@@ -5,8 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
-	"math/rand"
-
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	starknetutils "github.com/NethermindEth/starknet.go/utils"
 	"github.com/pelletier/go-toml/v2"
 	"go.uber.org/multierr"
 
@@ -18,7 +24,6 @@ import (
 	"github.com/smartcontractkit/chainlink-common/pkg/utils"
 
 	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/config"
-	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/db"
 	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm"
 	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
 )
@@ -37,6 +42,8 @@ type ChainOpts struct {
 	Logger logger.Logger
 	// the implementation used here needs to be co-ordinated with the starknet transaction manager keystore adapter
 	KeyStore loop.Keystore
+	// Paymaster optionally sponsors the fees of all txs, overriding the paymaster set in the config
+	Paymaster txm.Paymaster
 }
 
 func (o *ChainOpts) Name() string {
@@ -64,33 +71,56 @@ type chain struct {
 	cfg  *config.TOMLConfig
 	lggr logger.Logger
 	txm  txm.StarkTXM
+
+	// pool routes the requests of all clients to the best healthy node
+	pool   *starknet.Pool
+	client *starknet.Client
+
+	done sync.WaitGroup
+	stop chan struct{}
 }
 
 func NewChain(cfg *config.TOMLConfig, opts ChainOpts) (Chain, error) {
 	if !cfg.IsEnabled() {
 		return nil, fmt.Errorf("cannot create new chain with ID %s: chain is disabled", *cfg.ChainID)
 	}
-	c, err := newChain(*cfg.ChainID, cfg, opts.KeyStore, opts.Logger)
+	c, err := newChain(*cfg.ChainID, cfg, opts.KeyStore, opts.Paymaster, opts.Logger)
 	if err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
-func newChain(id string, cfg *config.TOMLConfig, loopKs loop.Keystore, lggr logger.Logger) (*chain, error) {
+func newChain(id string, cfg *config.TOMLConfig, loopKs loop.Keystore, paymaster txm.Paymaster, lggr logger.Logger) (*chain, error) {
 	lggr = logger.With(lggr, "starknetChainID", id)
 	ch := &chain{
 		id:   id,
 		cfg:  cfg,
 		lggr: logger.Named(lggr, "Chain"),
+		stop: make(chan struct{}),
+	}
+
+	nodes, err := cfg.ListNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nodes: %w", err)
+	}
+	poolNodes := make([]starknet.PoolNode, len(nodes))
+	for i, node := range nodes {
+		poolNodes[i] = starknet.PoolNode{Name: node.Name, URL: node.URL, WSURL: node.WSURL}
 	}
+	ch.pool = starknet.NewPool(id, poolNodes, ch.lggr, cfg.RequestTimeout(), cfg.NodeMaxSyncLag(), cfg.RetryConfig())
+	ch.client = ch.pool.Client()
 
 	getClient := func() (*starknet.Client, error) {
 		return ch.getClient()
 	}
 
-	var err error
-	ch.txm, err = txm.New(lggr, loopKs, cfg, getClient)
+	if signerURL := cfg.RemoteSignerURL(); signerURL != nil {
+		// keys are held by the remote signer instead of the node keystore
+		loopKs = txm.NewRemoteKeystore(signerURL, &http.Client{Timeout: cfg.RemoteSignerTimeout()})
+	}
+
+	ch.txm, err = txm.New(lggr, loopKs, cfg, getClient, paymaster)
 	if err != nil {
 		return nil, err
 	}
@@ -118,49 +148,29 @@ func (c *chain) ChainID() string {
 	return c.id
 }
 
-// getClient returns a client, randomly selecting one from available and valid nodes
+// getClient returns a client that routes requests through the node pool, failing over between nodes
 func (c *chain) getClient() (*starknet.Client, error) {
-	var node db.Node
-	var client *starknet.Client
-	nodes, err := c.cfg.ListNodes()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get nodes: %w", err)
-	}
-	if len(nodes) == 0 {
-		return nil, errors.New("no nodes available")
-	}
-	// #nosec
-	index := rand.Perm(len(nodes)) // list of node indexes to try
-	timeout := c.cfg.RequestTimeout()
-	for _, i := range index {
-		node = nodes[i]
-		// create client and check
-		client, err = starknet.NewClient(node.ChainID, node.URL, c.lggr, &timeout)
-		// if error, try another node
-		if err != nil {
-			c.lggr.Warnw("failed to create node", "name", node.Name, "starknet-url", node.URL, "err", err.Error())
-			continue
-		}
-		// if all checks passed, mark found and break loop
-		break
-	}
-	// if no valid node found, exit with error
-	if client == nil {
-		return nil, errors.New("no node valid nodes available")
-	}
-	c.lggr.Debugw("Created client", "name", node.Name, "starknet-url", node.URL)
-	return client, nil
+	return c.client, nil
 }
 
 func (c *chain) Start(ctx context.Context) error {
 	return c.StartOnce("Chain", func() error {
-		return c.txm.Start(ctx)
+		if err := c.txm.Start(ctx); err != nil {
+			return err
+		}
+		if c.cfg.NodePollInterval() > 0 {
+			c.done.Add(1)
+			go c.nodeLoop()
+		}
+		return nil
 	})
 }
 
 func (c *chain) Close() error {
 	return c.StopOnce("Chain", func() error {
-		return c.txm.Close()
+		close(c.stop)
+		c.done.Wait()
+		return errors.Join(c.txm.Close(), c.pool.Close())
 	})
 }
 
@@ -171,6 +181,9 @@ func (c *chain) Ready() error {
 func (c *chain) HealthReport() map[string]error {
 	report := map[string]error{c.Name(): c.Healthy()}
 	services.CopyHealth(report, c.txm.HealthReport())
+	for name, err := range c.nodeHealth() {
+		report[c.Name()+".Node."+name] = err
+	}
 	return report
 }
 
@@ -187,7 +200,7 @@ func (c *chain) GetChainStatus(ctx context.Context) (types.ChainStatus, error) {
 	return types.ChainStatus{
 		ID:      c.id,
 		Enabled: c.cfg.IsEnabled(),
-		Config:  toml,
+		Config:  toml + c.nodesComment(),
 	}, nil
 }
 
@@ -195,15 +208,114 @@ func (c *chain) ListNodeStatuses(ctx context.Context, pageSize int32, pageToken
 	return chains.ListNodeStatuses(int(pageSize), pageToken, c.listNodeStatuses)
 }
 
+// Transact enqueues an ERC20 transfer of the fee token from the account to the address. With balanceCheck, the account
+// must hold the amount plus the estimated fee, which is paid in the same token.
 func (c *chain) Transact(ctx context.Context, from, to string, amount *big.Int, balanceCheck bool) error {
-	return errors.ErrUnsupported
+	token := c.cfg.FeeTokenAddress()
+	if token == nil {
+		return fmt.Errorf("transfers require FeeTokenAddress: %w", errors.ErrUnsupported)
+	}
+	fromAddress, err := starknetutils.HexToFelt(from)
+	if err != nil {
+		return fmt.Errorf("invalid from address %s: %w", from, err)
+	}
+	toAddress, err := starknetutils.HexToFelt(to)
+	if err != nil {
+		return fmt.Errorf("invalid to address %s: %w", to, err)
+	}
+	low, high, err := toUint256(amount)
+	if err != nil {
+		return fmt.Errorf("invalid amount %s: %w", amount, err)
+	}
+
+	client, err := c.getClient()
+	if err != nil {
+		return err
+	}
+	publicKey, err := accountPublicKey(ctx, client, fromAddress)
+	if err != nil {
+		return err
+	}
+	call := starknetrpc.FunctionCall{
+		ContractAddress:    token,
+		EntryPointSelector: starknetutils.GetSelectorFromNameFelt("transfer"),
+		Calldata:           []*felt.Felt{toAddress, low, high},
+	}
+
+	if balanceCheck {
+		balance, err := client.BalanceOf(ctx, token, fromAddress)
+		if err != nil {
+			return err
+		}
+		fee, err := c.txm.EstimateFee(ctx, fromAddress, publicKey, []starknetrpc.FunctionCall{call})
+		if err != nil {
+			return err
+		}
+		if required := new(big.Int).Add(amount, fee); balance.Cmp(required) < 0 {
+			return fmt.Errorf("insufficient balance of %s: %s < %s amount + %s estimated fee", from, balance, amount, fee)
+		}
+	}
+
+	id, err := c.txm.Enqueue(fromAddress, publicKey, call, txm.TxOpts{})
+	if err != nil {
+		return err
+	}
+	c.lggr.Infow("enqueued transfer", "id", id, "from", from, "to", to, "amount", amount, "token", token)
+	return nil
 }
 
 func (c *chain) SendTx(ctx context.Context, from, to string, amount *big.Int, balanceCheck bool) error {
 	return c.Transact(ctx, from, to, amount, balanceCheck)
 }
 
-// TODO BCF-2602 statuses are static for non-evm chain and should be dynamic
+// publicKeySelectors are the getters of the signer public key of common account contracts: OpenZeppelin (Cairo 1 and 0)
+// and Argent (Cairo 1 and 0)
+var publicKeySelectors = []string{"get_public_key", "getPublicKey", "get_owner", "getSigner"}
+
+// accountPublicKey returns the public key of the account signer, the keystore holds keys by public key
+func accountPublicKey(ctx context.Context, client *starknet.Client, account *felt.Felt) (*felt.Felt, error) {
+	var errs error
+	for _, name := range publicKeySelectors {
+		out, err := client.CallContract(ctx, starknet.CallOps{
+			ContractAddress: account,
+			Selector:        starknetutils.GetSelectorFromNameFelt(name),
+		})
+		if err == nil && len(out) == 1 {
+			return out[0], nil
+		}
+		errs = multierr.Append(errs, err)
+	}
+	return nil, fmt.Errorf("failed to get public key of account %s: %w", account, errs)
+}
+
+// toUint256 splits the amount into the low and high 128 bits of a Cairo u256
+func toUint256(amount *big.Int) (low, high *felt.Felt, err error) {
+	if amount == nil || amount.Sign() < 0 || amount.BitLen() > 256 {
+		return nil, nil, errors.New("must be a u256")
+	}
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	low = starknetutils.BigIntToFelt(new(big.Int).And(amount, mask))
+	high = starknetutils.BigIntToFelt(new(big.Int).Rsh(amount, 128))
+	return low, high, nil
+}
+
+// nodesComment summarizes the live state of the nodes as a TOML comment
+func (c *chain) nodesComment() string {
+	var alive int
+	primary := "none"
+	states := c.pool.States()
+	for _, state := range states {
+		if state.State() == starknet.NodeStateAlive {
+			alive++
+		}
+		if state.Primary {
+			primary = fmt.Sprintf("'%s' at block %d", state.Name, state.Height)
+		}
+	}
+	return fmt.Sprintf("# Nodes: %d/%d alive, primary %s\n", alive, len(states), primary)
+}
+
+// listNodeStatuses returns the config and the live state of the nodes from the last probe of the pool
 func (c *chain) listNodeStatuses(start, end int) ([]types.NodeStatus, int, error) {
 	stats := make([]types.NodeStatus, 0)
 	total := len(c.cfg.Nodes)
@@ -213,9 +325,13 @@ func (c *chain) listNodeStatuses(start, end int) ([]types.NodeStatus, int, error
 	if end <= 0 || end > total {
 		end = total
 	}
+	states := map[string]starknet.NodeState{}
+	for _, state := range c.pool.States() {
+		states[state.Name] = state
+	}
 	nodes := c.cfg.Nodes[start:end]
 	for _, node := range nodes {
-		stat, err := nodeStatus(node, c.ChainID())
+		stat, err := nodeStatus(node, c.ChainID(), states[*node.Name])
 		if err != nil {
 			return stats, total, err
 		}
@@ -224,14 +340,32 @@ func (c *chain) listNodeStatuses(start, end int) ([]types.NodeStatus, int, error
 	return stats, total, nil
 }
 
-func nodeStatus(n *config.Node, id string) (types.NodeStatus, error) {
+func nodeStatus(n *config.Node, id string, state starknet.NodeState) (types.NodeStatus, error) {
 	var s types.NodeStatus
 	s.ChainID = id
 	s.Name = *n.Name
+	s.State = state.State()
 	b, err := toml.Marshal(n)
 	if err != nil {
 		return types.NodeStatus{}, err
 	}
-	s.Config = string(b)
+	s.Config = string(b) + nodeStateComment(state)
 	return s, nil
 }
+
+// nodeStateComment renders the live state of a node as TOML comments, so that the status config stays valid node config
+func nodeStateComment(state starknet.NodeState) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# State = '%s'\n", state.State())
+	fmt.Fprintf(&b, "# Primary = %t\n", state.Primary)
+	if !state.CheckedAt.IsZero() {
+		fmt.Fprintf(&b, "# SpecVersion = '%s'\n", state.SpecVersion)
+		fmt.Fprintf(&b, "# LatestBlock = %d\n", state.Height)
+		fmt.Fprintf(&b, "# Latency = '%s'\n", state.Latency)
+		fmt.Fprintf(&b, "# CheckedAt = '%s'\n", state.CheckedAt.UTC().Format(time.RFC3339))
+	}
+	if state.LastErr != nil {
+		fmt.Fprintf(&b, "# LastError = %q\n", state.LastErr.Error())
+	}
+	return b.String()
+}
@@ -0,0 +1,133 @@
+package starknet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetutils "github.com/NethermindEth/starknet.go/utils"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonconfig "github.com/smartcontractkit/chainlink-common/pkg/config"
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/config"
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+func TestToUint256(t *testing.T) {
+	amount := new(big.Int).Lsh(big.NewInt(3), 128)
+	amount.Add(amount, big.NewInt(7))
+	low, high, err := toUint256(amount)
+	require.NoError(t, err)
+	assert.Equal(t, new(felt.Felt).SetUint64(7), low)
+	assert.Equal(t, new(felt.Felt).SetUint64(3), high)
+
+	_, _, err = toUint256(big.NewInt(-1))
+	assert.Error(t, err)
+	_, _, err = toUint256(new(big.Int).Lsh(big.NewInt(1), 256))
+	assert.Error(t, err)
+}
+
+// newNodeServer returns a node of the chain that is at the given block height
+func newNodeServer(t *testing.T, chainID string, height uint64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		result := fmt.Sprint(height)
+		switch req.Method {
+		case "starknet_chainId":
+			result = fmt.Sprintf("%q", starknetutils.BigToHex(starknetutils.UTF8StrToBig(chainID)))
+		case "starknet_specVersion":
+			result = fmt.Sprintf("%q", starknet.MinSpecVersion)
+		}
+		_, err := fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 1, "result": %s}`, result)
+		require.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestChain_NodeHealth(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(down.Close)
+
+	chainID := "SN_NODE_HEALTH"
+	node := func(name, url string) *config.Node {
+		return &config.Node{Name: &name, URL: commonconfig.MustParseURL(url)}
+	}
+	cfg := &config.TOMLConfig{ChainID: &chainID, Nodes: config.Nodes{
+		node("primary", newNodeServer(t, chainID, 100).URL),
+		node("lagging", newNodeServer(t, chainID, 80).URL),
+		node("other-chain", newNodeServer(t, "SN_OTHER", 100).URL),
+		node("down", down.URL),
+	}}
+	cfg.SetDefaults()
+
+	c, err := newChain(chainID, cfg, nil, nil, logger.Test(t))
+	require.NoError(t, err)
+	// nodes are healthy until they are probed
+	report := c.nodeHealth()
+	require.Len(t, report, 4)
+	for name, err := range report {
+		assert.NoError(t, err, name)
+	}
+
+	c.pool.Probe(context.Background())
+	report = c.nodeHealth()
+	assert.NoError(t, report["primary"])
+	assert.ErrorIs(t, report["lagging"], starknet.ErrNodeBehind)
+	assert.ErrorIs(t, report["other-chain"], starknet.ErrChainIDMismatch)
+	assert.ErrorIs(t, report["down"], starknet.ErrNodeUnreachable)
+
+	// node entries are namespaced under the chain
+	assert.Contains(t, c.HealthReport(), c.Name()+".Node.lagging")
+}
+
+func TestChain_ListNodeStatuses(t *testing.T) {
+	chainID := "SN_NODE_STATUS"
+	node := func(name, url string) *config.Node {
+		return &config.Node{Name: &name, URL: commonconfig.MustParseURL(url)}
+	}
+	cfg := &config.TOMLConfig{ChainID: &chainID, Nodes: config.Nodes{
+		node("alive", newNodeServer(t, chainID, 100).URL),
+		node("wrong-chain", newNodeServer(t, "SN_OTHER", 100).URL),
+	}}
+	cfg.SetDefaults()
+
+	c, err := newChain(chainID, cfg, nil, nil, logger.Test(t))
+	require.NoError(t, err)
+
+	stats, _, total, err := c.ListNodeStatuses(context.Background(), 0, "")
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	assert.Equal(t, starknet.NodeStateUnknown, stats[0].State)
+
+	c.pool.Probe(context.Background())
+	stats, _, _, err = c.ListNodeStatuses(context.Background(), 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, starknet.NodeStateAlive, stats[0].State)
+	assert.Contains(t, stats[0].Config, "# LatestBlock = 100\n")
+	assert.Contains(t, stats[0].Config, "# Primary = true\n")
+	assert.Contains(t, stats[0].Config, "# SpecVersion = '0.6.0'\n")
+	assert.Equal(t, starknet.NodeStateWrongChain, stats[1].State)
+	assert.Contains(t, stats[1].Config, "# LastError = ")
+
+	// the live state is kept in comments, the config still decodes as a node
+	var decoded config.Node
+	require.NoError(t, toml.Unmarshal([]byte(stats[1].Config), &decoded))
+	assert.Equal(t, "wrong-chain", *decoded.Name)
+
+	status, err := c.GetChainStatus(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, status.Config, "# Nodes: 1/2 alive, primary 'alive' at block 100\n")
+}
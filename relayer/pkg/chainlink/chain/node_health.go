@@ -0,0 +1,36 @@
+package starknet
+
+import (
+	"time"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/utils"
+)
+
+// nodeLoop periodically probes every node of the pool, which re-ranks them
+func (c *chain) nodeLoop() {
+	defer c.done.Done()
+
+	ctx, cancel := utils.ContextFromChan(c.stop)
+	defer cancel()
+
+	c.pool.Probe(ctx)
+	for {
+		select {
+		case <-time.After(utils.WithJitter(c.cfg.NodePollInterval())):
+			c.pool.Probe(ctx)
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// nodeHealth reports every node by name, nodes are unhealthy if their last probe or request failed,
+// if they serve another chain, or if they lag behind the highest node by more than the max sync lag
+func (c *chain) nodeHealth() map[string]error {
+	states := c.pool.States()
+	report := make(map[string]error, len(states))
+	for _, state := range states {
+		report[state.Name] = state.Err
+	}
+	return report
+}
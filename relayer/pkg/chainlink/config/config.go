@@ -2,10 +2,14 @@ package config
 
 import (
 	"fmt"
+	"math/big"
 	"net/url"
 	"slices"
 	"time"
 
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	starknetutils "github.com/NethermindEth/starknet.go/utils"
 	"github.com/pelletier/go-toml/v2"
 	"go.uber.org/multierr"
 
@@ -14,14 +18,60 @@ import (
 	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/db"
 	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/ocr2"
 	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm"
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
 )
 
+// mainnetChainID is the chain id of Starknet mainnet
+const mainnetChainID = "SN_MAIN"
+
 var DefaultConfigSet = ConfigSet{
 	OCR2CachePollPeriod: 5 * time.Second,
 	OCR2CacheTTL:        time.Minute,
 	RequestTimeout:      10 * time.Second,
 	TxTimeout:           10 * time.Second,
 	ConfirmationPoll:    5 * time.Second,
+	StuckTxTimeout:      2 * time.Minute,
+	NonceSyncInterval:   time.Minute,
+	BatchWindow:         0,
+	MaxBatchSize:        10,
+	MaxBatchGas:         0,
+	TxStoreRetention:    24 * time.Hour,
+	L1GasAmountPercent:  115,
+	L1GasPricePercent:   150, // the L1 gas price follows Ethereum's and needs more headroom than L2
+	L2GasAmountPercent:  0,   // the fee estimate only covers L1 gas, see txm.FeePolicy
+	L2GasPricePercent:   0,
+	L1GasMaxPrice:       0,
+	L2GasMaxPrice:       0,
+	MaxFee:              0,
+	TipStrategy:         string(txm.TipNone),
+	Tip:                 0,
+	NonceDAMode:         string(starknetrpc.DAModeL1),
+	FeeDAMode:           string(starknetrpc.DAModeL1),
+	SimulateTxs:         false,
+	DryRun:              false,
+	FinalityTarget:      string(starknetrpc.TxnStatus_Accepted_On_L2),
+	AccountClassHash:    "",
+	AccountSalt:         "",
+	PaymasterAddress:    "",
+	RemoteSignerTimeout: 10 * time.Second,
+	FeeTokenAddress:     "0x04718f5a0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d", // STRK
+	BalancePollInterval: 0,
+	MinBalance:          "0",
+	BalanceGating:       false,
+
+	QueueSaturationPercent: 80,
+	MaxUnconfirmedAge:      10 * time.Minute,
+	MaxNonceErrors:         3,
+	NodePollInterval:       15 * time.Second,
+	NodeMaxSyncLag:         10,
+
+	ReadMaxRetries:          starknet.DefaultRetryConfig.Read.MaxRetries,
+	WriteMaxRetries:         starknet.DefaultRetryConfig.Write.MaxRetries,
+	SimulateMaxRetries:      starknet.DefaultRetryConfig.Simulate.MaxRetries,
+	RetryInitialBackoff:     starknet.DefaultRetryConfig.Read.InitialBackoff,
+	RetryMaxBackoff:         starknet.DefaultRetryConfig.Read.MaxBackoff,
+	CircuitBreakerThreshold: starknet.DefaultRetryConfig.BreakerThreshold,
+	CircuitBreakerCooldown:  starknet.DefaultRetryConfig.BreakerCooldown,
 }
 
 type ConfigSet struct {
@@ -32,8 +82,71 @@ type ConfigSet struct {
 	RequestTimeout time.Duration
 
 	// txm config
-	TxTimeout        time.Duration
-	ConfirmationPoll time.Duration
+	TxTimeout         time.Duration
+	ConfirmationPoll  time.Duration
+	StuckTxTimeout    time.Duration
+	NonceSyncInterval time.Duration
+	BatchWindow       time.Duration
+	MaxBatchSize      uint32
+	MaxBatchGas       uint64
+	TxStorePath       string
+	TxStoreRetention  time.Duration // how long txs are kept once terminal and finalized
+
+	// fee policy
+	L1GasAmountPercent uint32
+	L1GasPricePercent  uint32
+	L2GasAmountPercent uint32
+	L2GasPricePercent  uint32
+	L1GasMaxPrice      uint64
+	L2GasMaxPrice      uint64
+	MaxFee             uint64
+	TipStrategy        string
+	Tip                uint64
+	NonceDAMode        string
+	FeeDAMode          string
+
+	SimulateTxs bool
+	DryRun      bool
+
+	FinalityTarget string
+
+	// account deploys, disabled if empty
+	AccountClassHash string
+	AccountSalt      string
+
+	// SNIP-8 paymaster that sponsors all txs, disabled if empty
+	PaymasterAddress string
+	PaymasterData    []string
+
+	// remote signer, keys are held by the node keystore if no URL is set.
+	// The URL must be https unless RemoteSignerInsecure is set, e.g. for a signer on localhost
+	RemoteSignerTimeout  time.Duration
+	RemoteSignerInsecure bool
+
+	// ERC20 token sent by chain transfers, STRK pays the fees of V3 txs. Transfers and balance monitoring are disabled if empty
+	FeeTokenAddress string
+
+	// balance monitor, off unless BalancePollInterval is set. MinBalance is a decimal amount in the smallest unit
+	// of the fee token, i.e. FRI for STRK or wei for ETH
+	BalancePollInterval time.Duration
+	MinBalance          string
+	BalanceGating       bool
+
+	// health check thresholds, 0 disables a check
+	QueueSaturationPercent uint32
+	MaxUnconfirmedAge      time.Duration
+	MaxNonceErrors         uint32
+	NodePollInterval       time.Duration
+	NodeMaxSyncLag         uint64 // blocks behind the highest node
+
+	// client retries per method class, the backoff is shared. A threshold of 0 disables the circuit breaker of each node
+	ReadMaxRetries          uint32
+	WriteMaxRetries         uint32
+	SimulateMaxRetries      uint32
+	RetryInitialBackoff     time.Duration
+	RetryMaxBackoff         time.Duration
+	CircuitBreakerThreshold uint32
+	CircuitBreakerCooldown  time.Duration
 }
 
 type Config interface {
@@ -44,6 +157,17 @@ type Config interface {
 
 	// client config
 	RequestTimeout() time.Duration
+
+	// remote signer config
+	RemoteSignerURL() *url.URL
+	RemoteSignerTimeout() time.Duration
+
+	// node health config
+	NodePollInterval() time.Duration
+	NodeMaxSyncLag() uint64
+
+	// client retry config
+	RetryConfig() starknet.RetryConfig
 }
 
 type Chain struct {
@@ -52,6 +176,53 @@ type Chain struct {
 	RequestTimeout      *config.Duration
 	TxTimeout           *config.Duration
 	ConfirmationPoll    *config.Duration
+	StuckTxTimeout      *config.Duration
+	NonceSyncInterval   *config.Duration
+	BatchWindow         *config.Duration
+	MaxBatchSize        *uint32
+	MaxBatchGas         *uint64
+	TxStorePath         *string
+	TxStoreRetention    *config.Duration
+	L1GasAmountPercent  *uint32
+	L1GasPricePercent   *uint32
+	L2GasAmountPercent  *uint32
+	L2GasPricePercent   *uint32
+	L1GasMaxPrice       *uint64
+	L2GasMaxPrice       *uint64
+	MaxFee              *uint64
+	TipStrategy         *string
+	Tip                 *uint64
+	NonceDAMode         *string
+	FeeDAMode           *string
+	SimulateTxs         *bool
+	DryRun              *bool
+	FinalityTarget      *string
+	AccountClassHash    *string
+	AccountSalt         *string
+	PaymasterAddress    *string
+	PaymasterData       []string
+	RemoteSignerURL     *config.URL
+	RemoteSignerTimeout *config.Duration
+	FeeTokenAddress     *string
+	BalancePollInterval *config.Duration
+	MinBalance          *string
+	BalanceGating       *bool
+
+	QueueSaturationPercent *uint32
+	MaxUnconfirmedAge      *config.Duration
+	MaxNonceErrors         *uint32
+	NodePollInterval       *config.Duration
+	NodeMaxSyncLag         *uint64
+
+	ReadMaxRetries          *uint32
+	WriteMaxRetries         *uint32
+	SimulateMaxRetries      *uint32
+	RetryInitialBackoff     *config.Duration
+	RetryMaxBackoff         *config.Duration
+	CircuitBreakerThreshold *uint32
+	CircuitBreakerCooldown  *config.Duration
+
+	RemoteSignerInsecure *bool
 }
 
 func (c *Chain) SetDefaults() {
@@ -70,11 +241,171 @@ func (c *Chain) SetDefaults() {
 	if c.ConfirmationPoll == nil {
 		c.ConfirmationPoll = config.MustNewDuration(DefaultConfigSet.ConfirmationPoll)
 	}
+	if c.StuckTxTimeout == nil {
+		c.StuckTxTimeout = config.MustNewDuration(DefaultConfigSet.StuckTxTimeout)
+	}
+	if c.NonceSyncInterval == nil {
+		c.NonceSyncInterval = config.MustNewDuration(DefaultConfigSet.NonceSyncInterval)
+	}
+	if c.BatchWindow == nil {
+		c.BatchWindow = config.MustNewDuration(DefaultConfigSet.BatchWindow)
+	}
+	if c.MaxBatchSize == nil {
+		size := DefaultConfigSet.MaxBatchSize
+		c.MaxBatchSize = &size
+	}
+	if c.MaxBatchGas == nil {
+		gas := DefaultConfigSet.MaxBatchGas
+		c.MaxBatchGas = &gas
+	}
+	if c.TxStorePath == nil {
+		path := DefaultConfigSet.TxStorePath
+		c.TxStorePath = &path
+	}
+	if c.TxStoreRetention == nil {
+		c.TxStoreRetention = config.MustNewDuration(DefaultConfigSet.TxStoreRetention)
+	}
+	if c.L1GasAmountPercent == nil {
+		percent := DefaultConfigSet.L1GasAmountPercent
+		c.L1GasAmountPercent = &percent
+	}
+	if c.L1GasPricePercent == nil {
+		percent := DefaultConfigSet.L1GasPricePercent
+		c.L1GasPricePercent = &percent
+	}
+	if c.L2GasAmountPercent == nil {
+		percent := DefaultConfigSet.L2GasAmountPercent
+		c.L2GasAmountPercent = &percent
+	}
+	if c.L2GasPricePercent == nil {
+		percent := DefaultConfigSet.L2GasPricePercent
+		c.L2GasPricePercent = &percent
+	}
+	if c.L1GasMaxPrice == nil {
+		price := DefaultConfigSet.L1GasMaxPrice
+		c.L1GasMaxPrice = &price
+	}
+	if c.L2GasMaxPrice == nil {
+		price := DefaultConfigSet.L2GasMaxPrice
+		c.L2GasMaxPrice = &price
+	}
+	if c.MaxFee == nil {
+		fee := DefaultConfigSet.MaxFee
+		c.MaxFee = &fee
+	}
+	if c.TipStrategy == nil {
+		strategy := DefaultConfigSet.TipStrategy
+		c.TipStrategy = &strategy
+	}
+	if c.Tip == nil {
+		tip := DefaultConfigSet.Tip
+		c.Tip = &tip
+	}
+	if c.NonceDAMode == nil {
+		mode := DefaultConfigSet.NonceDAMode
+		c.NonceDAMode = &mode
+	}
+	if c.FeeDAMode == nil {
+		mode := DefaultConfigSet.FeeDAMode
+		c.FeeDAMode = &mode
+	}
+	if c.SimulateTxs == nil {
+		simulate := DefaultConfigSet.SimulateTxs
+		c.SimulateTxs = &simulate
+	}
+	if c.DryRun == nil {
+		dryRun := DefaultConfigSet.DryRun
+		c.DryRun = &dryRun
+	}
+	if c.FinalityTarget == nil {
+		target := DefaultConfigSet.FinalityTarget
+		c.FinalityTarget = &target
+	}
+	if c.AccountClassHash == nil {
+		classHash := DefaultConfigSet.AccountClassHash
+		c.AccountClassHash = &classHash
+	}
+	if c.AccountSalt == nil {
+		salt := DefaultConfigSet.AccountSalt
+		c.AccountSalt = &salt
+	}
+	if c.PaymasterAddress == nil {
+		address := DefaultConfigSet.PaymasterAddress
+		c.PaymasterAddress = &address
+	}
+	if c.RemoteSignerTimeout == nil {
+		c.RemoteSignerTimeout = config.MustNewDuration(DefaultConfigSet.RemoteSignerTimeout)
+	}
+	if c.RemoteSignerInsecure == nil {
+		insecure := DefaultConfigSet.RemoteSignerInsecure
+		c.RemoteSignerInsecure = &insecure
+	}
+	if c.FeeTokenAddress == nil {
+		address := DefaultConfigSet.FeeTokenAddress
+		c.FeeTokenAddress = &address
+	}
+	if c.BalancePollInterval == nil {
+		c.BalancePollInterval = config.MustNewDuration(DefaultConfigSet.BalancePollInterval)
+	}
+	if c.MinBalance == nil {
+		balance := DefaultConfigSet.MinBalance
+		c.MinBalance = &balance
+	}
+	if c.BalanceGating == nil {
+		gating := DefaultConfigSet.BalanceGating
+		c.BalanceGating = &gating
+	}
+	if c.QueueSaturationPercent == nil {
+		percent := DefaultConfigSet.QueueSaturationPercent
+		c.QueueSaturationPercent = &percent
+	}
+	if c.MaxUnconfirmedAge == nil {
+		c.MaxUnconfirmedAge = config.MustNewDuration(DefaultConfigSet.MaxUnconfirmedAge)
+	}
+	if c.MaxNonceErrors == nil {
+		maxErrors := DefaultConfigSet.MaxNonceErrors
+		c.MaxNonceErrors = &maxErrors
+	}
+	if c.NodePollInterval == nil {
+		c.NodePollInterval = config.MustNewDuration(DefaultConfigSet.NodePollInterval)
+	}
+	if c.NodeMaxSyncLag == nil {
+		lag := DefaultConfigSet.NodeMaxSyncLag
+		c.NodeMaxSyncLag = &lag
+	}
+	if c.ReadMaxRetries == nil {
+		retries := DefaultConfigSet.ReadMaxRetries
+		c.ReadMaxRetries = &retries
+	}
+	if c.WriteMaxRetries == nil {
+		retries := DefaultConfigSet.WriteMaxRetries
+		c.WriteMaxRetries = &retries
+	}
+	if c.SimulateMaxRetries == nil {
+		retries := DefaultConfigSet.SimulateMaxRetries
+		c.SimulateMaxRetries = &retries
+	}
+	if c.RetryInitialBackoff == nil {
+		c.RetryInitialBackoff = config.MustNewDuration(DefaultConfigSet.RetryInitialBackoff)
+	}
+	if c.RetryMaxBackoff == nil {
+		c.RetryMaxBackoff = config.MustNewDuration(DefaultConfigSet.RetryMaxBackoff)
+	}
+	if c.CircuitBreakerThreshold == nil {
+		threshold := DefaultConfigSet.CircuitBreakerThreshold
+		c.CircuitBreakerThreshold = &threshold
+	}
+	if c.CircuitBreakerCooldown == nil {
+		c.CircuitBreakerCooldown = config.MustNewDuration(DefaultConfigSet.CircuitBreakerCooldown)
+	}
 }
 
 type Node struct {
 	Name *string
 	URL  *config.URL
+	// WSURL is the WebSocket endpoint of the node, optional. Caches and the TXM react to its pushes,
+	// polling stays as a fallback. It must serve RPC spec 0.8, which added subscriptions.
+	WSURL *config.URL
 }
 
 type TOMLConfigs []*TOMLConfig
@@ -172,6 +503,138 @@ func setFromChain(c, f *Chain) {
 	if f.ConfirmationPoll != nil {
 		c.ConfirmationPoll = f.ConfirmationPoll
 	}
+	if f.StuckTxTimeout != nil {
+		c.StuckTxTimeout = f.StuckTxTimeout
+	}
+	if f.NonceSyncInterval != nil {
+		c.NonceSyncInterval = f.NonceSyncInterval
+	}
+	if f.BatchWindow != nil {
+		c.BatchWindow = f.BatchWindow
+	}
+	if f.MaxBatchSize != nil {
+		c.MaxBatchSize = f.MaxBatchSize
+	}
+	if f.MaxBatchGas != nil {
+		c.MaxBatchGas = f.MaxBatchGas
+	}
+	if f.TxStorePath != nil {
+		c.TxStorePath = f.TxStorePath
+	}
+	if f.TxStoreRetention != nil {
+		c.TxStoreRetention = f.TxStoreRetention
+	}
+	if f.L1GasAmountPercent != nil {
+		c.L1GasAmountPercent = f.L1GasAmountPercent
+	}
+	if f.L1GasPricePercent != nil {
+		c.L1GasPricePercent = f.L1GasPricePercent
+	}
+	if f.L2GasAmountPercent != nil {
+		c.L2GasAmountPercent = f.L2GasAmountPercent
+	}
+	if f.L2GasPricePercent != nil {
+		c.L2GasPricePercent = f.L2GasPricePercent
+	}
+	if f.L1GasMaxPrice != nil {
+		c.L1GasMaxPrice = f.L1GasMaxPrice
+	}
+	if f.L2GasMaxPrice != nil {
+		c.L2GasMaxPrice = f.L2GasMaxPrice
+	}
+	if f.MaxFee != nil {
+		c.MaxFee = f.MaxFee
+	}
+	if f.TipStrategy != nil {
+		c.TipStrategy = f.TipStrategy
+	}
+	if f.Tip != nil {
+		c.Tip = f.Tip
+	}
+	if f.NonceDAMode != nil {
+		c.NonceDAMode = f.NonceDAMode
+	}
+	if f.FeeDAMode != nil {
+		c.FeeDAMode = f.FeeDAMode
+	}
+	if f.SimulateTxs != nil {
+		c.SimulateTxs = f.SimulateTxs
+	}
+	if f.DryRun != nil {
+		c.DryRun = f.DryRun
+	}
+	if f.FinalityTarget != nil {
+		c.FinalityTarget = f.FinalityTarget
+	}
+	if f.AccountClassHash != nil {
+		c.AccountClassHash = f.AccountClassHash
+	}
+	if f.AccountSalt != nil {
+		c.AccountSalt = f.AccountSalt
+	}
+	if f.PaymasterAddress != nil {
+		c.PaymasterAddress = f.PaymasterAddress
+	}
+	if f.PaymasterData != nil {
+		c.PaymasterData = f.PaymasterData
+	}
+	if f.RemoteSignerURL != nil {
+		c.RemoteSignerURL = f.RemoteSignerURL
+	}
+	if f.RemoteSignerTimeout != nil {
+		c.RemoteSignerTimeout = f.RemoteSignerTimeout
+	}
+	if f.RemoteSignerInsecure != nil {
+		c.RemoteSignerInsecure = f.RemoteSignerInsecure
+	}
+	if f.FeeTokenAddress != nil {
+		c.FeeTokenAddress = f.FeeTokenAddress
+	}
+	if f.BalancePollInterval != nil {
+		c.BalancePollInterval = f.BalancePollInterval
+	}
+	if f.MinBalance != nil {
+		c.MinBalance = f.MinBalance
+	}
+	if f.BalanceGating != nil {
+		c.BalanceGating = f.BalanceGating
+	}
+	if f.QueueSaturationPercent != nil {
+		c.QueueSaturationPercent = f.QueueSaturationPercent
+	}
+	if f.MaxUnconfirmedAge != nil {
+		c.MaxUnconfirmedAge = f.MaxUnconfirmedAge
+	}
+	if f.MaxNonceErrors != nil {
+		c.MaxNonceErrors = f.MaxNonceErrors
+	}
+	if f.NodePollInterval != nil {
+		c.NodePollInterval = f.NodePollInterval
+	}
+	if f.NodeMaxSyncLag != nil {
+		c.NodeMaxSyncLag = f.NodeMaxSyncLag
+	}
+	if f.ReadMaxRetries != nil {
+		c.ReadMaxRetries = f.ReadMaxRetries
+	}
+	if f.WriteMaxRetries != nil {
+		c.WriteMaxRetries = f.WriteMaxRetries
+	}
+	if f.SimulateMaxRetries != nil {
+		c.SimulateMaxRetries = f.SimulateMaxRetries
+	}
+	if f.RetryInitialBackoff != nil {
+		c.RetryInitialBackoff = f.RetryInitialBackoff
+	}
+	if f.RetryMaxBackoff != nil {
+		c.RetryMaxBackoff = f.RetryMaxBackoff
+	}
+	if f.CircuitBreakerThreshold != nil {
+		c.CircuitBreakerThreshold = f.CircuitBreakerThreshold
+	}
+	if f.CircuitBreakerCooldown != nil {
+		c.CircuitBreakerCooldown = f.CircuitBreakerCooldown
+	}
 }
 
 func (c *TOMLConfig) ValidateConfig() (err error) {
@@ -184,6 +647,99 @@ func (c *TOMLConfig) ValidateConfig() (err error) {
 	if len(c.Nodes) == 0 {
 		err = multierr.Append(err, config.ErrMissing{Name: "Nodes", Msg: "must have at least one node"})
 	}
+	for i, n := range c.Nodes {
+		if u := (*url.URL)(n.WSURL); u != nil && u.Scheme != "ws" && u.Scheme != "wss" {
+			err = multierr.Append(err, config.ErrInvalid{Name: fmt.Sprintf("Nodes.%d.WSURL", i), Value: u.String(), Msg: "must be a ws or wss URL"})
+		}
+	}
+
+	// without a tx store a restart forgets all unconfirmed txs, which is only acceptable on test networks
+	if c.ChainID != nil && *c.ChainID == mainnetChainID && (c.Chain.TxStorePath == nil || *c.Chain.TxStorePath == "") {
+		err = multierr.Append(err, config.ErrMissing{Name: "TxStorePath", Msg: "required on " + mainnetChainID})
+	}
+	// the fee estimate has a single gas amount, scaling it into both resources would commit to paying for it twice
+	if c.Chain.L1GasAmountPercent != nil && *c.Chain.L1GasAmountPercent > 0 && c.Chain.L2GasAmountPercent != nil && *c.Chain.L2GasAmountPercent > 0 {
+		err = multierr.Append(err, config.ErrInvalid{Name: "L2GasAmountPercent", Value: *c.Chain.L2GasAmountPercent, Msg: "must be 0 when L1GasAmountPercent is set"})
+	}
+	if c.Chain.TxStoreRetention != nil && c.Chain.TxStoreRetention.Duration() <= 0 {
+		err = multierr.Append(err, config.ErrInvalid{Name: "TxStoreRetention", Value: c.Chain.TxStoreRetention.Duration(), Msg: "must be greater than 0"})
+	}
+	if c.Chain.MaxBatchSize != nil && *c.Chain.MaxBatchSize == 0 {
+		err = multierr.Append(err, config.ErrInvalid{Name: "MaxBatchSize", Value: *c.Chain.MaxBatchSize, Msg: "must be greater than 0"})
+	}
+	if c.Chain.TipStrategy != nil {
+		switch txm.TipStrategy(*c.Chain.TipStrategy) {
+		case txm.TipNone, txm.TipFixed, txm.TipPercent:
+		default:
+			err = multierr.Append(err, config.ErrInvalid{Name: "TipStrategy", Value: *c.Chain.TipStrategy, Msg: "must be one of none, fixed or percent"})
+		}
+	}
+	for _, mode := range []struct {
+		name  string
+		value *string
+	}{{"NonceDAMode", c.Chain.NonceDAMode}, {"FeeDAMode", c.Chain.FeeDAMode}} {
+		if mode.value != nil && *mode.value != string(starknetrpc.DAModeL1) && *mode.value != string(starknetrpc.DAModeL2) {
+			err = multierr.Append(err, config.ErrInvalid{Name: mode.name, Value: *mode.value, Msg: "must be L1 or L2"})
+		}
+	}
+	if c.Chain.FinalityTarget != nil {
+		switch starknetrpc.TxnStatus(*c.Chain.FinalityTarget) {
+		case starknetrpc.TxnStatus_Accepted_On_L2, starknetrpc.TxnStatus_Accepted_On_L1:
+		default:
+			err = multierr.Append(err, config.ErrInvalid{Name: "FinalityTarget", Value: *c.Chain.FinalityTarget, Msg: "must be ACCEPTED_ON_L2 or ACCEPTED_ON_L1"})
+		}
+	}
+	for _, f := range []struct {
+		name  string
+		value *string
+	}{{"AccountClassHash", c.Chain.AccountClassHash}, {"AccountSalt", c.Chain.AccountSalt}, {"PaymasterAddress", c.Chain.PaymasterAddress}, {"FeeTokenAddress", c.Chain.FeeTokenAddress}} {
+		if f.value == nil || *f.value == "" {
+			continue
+		}
+		if _, ferr := starknetutils.HexToFelt(*f.value); ferr != nil {
+			err = multierr.Append(err, config.ErrInvalid{Name: f.name, Value: *f.value, Msg: "must be a hex encoded felt"})
+		}
+	}
+	for i, data := range c.Chain.PaymasterData {
+		if _, ferr := starknetutils.HexToFelt(data); ferr != nil {
+			err = multierr.Append(err, config.ErrInvalid{Name: fmt.Sprintf("PaymasterData.%d", i), Value: data, Msg: "must be a hex encoded felt"})
+		}
+	}
+	if len(c.Chain.PaymasterData) > 0 && (c.Chain.PaymasterAddress == nil || *c.Chain.PaymasterAddress == "") {
+		err = multierr.Append(err, config.ErrInvalid{Name: "PaymasterData", Value: c.Chain.PaymasterData, Msg: "requires PaymasterAddress"})
+	}
+	var classHash, salt string
+	if c.Chain.AccountClassHash != nil {
+		classHash = *c.Chain.AccountClassHash
+	}
+	if c.Chain.AccountSalt != nil {
+		salt = *c.Chain.AccountSalt
+	}
+	if (classHash == "") != (salt == "") {
+		err = multierr.Append(err, config.ErrInvalid{Name: "AccountClassHash", Value: classHash, Msg: "must be set together with AccountSalt"})
+	}
+	if c.Chain.MinBalance != nil {
+		if b, ok := new(big.Int).SetString(*c.Chain.MinBalance, 10); !ok || b.Sign() < 0 {
+			err = multierr.Append(err, config.ErrInvalid{Name: "MinBalance", Value: *c.Chain.MinBalance, Msg: "must be a non-negative decimal integer"})
+		}
+	}
+	if c.Chain.QueueSaturationPercent != nil && *c.Chain.QueueSaturationPercent > 100 {
+		err = multierr.Append(err, config.ErrInvalid{Name: "QueueSaturationPercent", Value: *c.Chain.QueueSaturationPercent, Msg: "must be at most 100"})
+	}
+	if c.Chain.RetryInitialBackoff != nil && c.Chain.RetryMaxBackoff != nil && c.Chain.RetryMaxBackoff.Duration() < c.Chain.RetryInitialBackoff.Duration() {
+		err = multierr.Append(err, config.ErrInvalid{Name: "RetryMaxBackoff", Value: c.Chain.RetryMaxBackoff.Duration(), Msg: "must not be less than RetryInitialBackoff"})
+	}
+	if u := (*url.URL)(c.Chain.RemoteSignerURL); u != nil {
+		insecure := c.Chain.RemoteSignerInsecure != nil && *c.Chain.RemoteSignerInsecure
+		switch {
+		case u.Scheme == "https":
+		case u.Scheme == "http" && insecure:
+		case u.Scheme == "http":
+			err = multierr.Append(err, config.ErrInvalid{Name: "RemoteSignerURL", Value: u.String(), Msg: "must be an https URL, set RemoteSignerInsecure to allow http"})
+		default:
+			err = multierr.Append(err, config.ErrInvalid{Name: "RemoteSignerURL", Value: u.String(), Msg: "must be an https URL"})
+		}
+	}
 
 	return
 }
@@ -219,14 +775,21 @@ func setFromNode(n, f *Node) {
 	if f.URL != nil {
 		n.URL = f.URL
 	}
+	if f.WSURL != nil {
+		n.WSURL = f.WSURL
+	}
 }
 
 func legacyNode(n *Node, id string) db.Node {
-	return db.Node{
+	node := db.Node{
 		Name:    *n.Name,
 		ChainID: id,
 		URL:     (*url.URL)(n.URL).String(),
 	}
+	if n.WSURL != nil {
+		node.WSURL = (*url.URL)(n.WSURL).String()
+	}
+	return node
 }
 
 var _ Config = &TOMLConfig{}
@@ -239,6 +802,194 @@ func (c *TOMLConfig) ConfirmationPoll() time.Duration {
 	return c.Chain.ConfirmationPoll.Duration()
 }
 
+func (c *TOMLConfig) StuckTxTimeout() time.Duration {
+	return c.Chain.StuckTxTimeout.Duration()
+}
+
+func (c *TOMLConfig) NonceSyncInterval() time.Duration {
+	return c.Chain.NonceSyncInterval.Duration()
+}
+
+func (c *TOMLConfig) BatchWindow() time.Duration {
+	return c.Chain.BatchWindow.Duration()
+}
+
+func (c *TOMLConfig) MaxBatchSize() int {
+	return int(*c.Chain.MaxBatchSize)
+}
+
+func (c *TOMLConfig) MaxBatchGas() uint64 {
+	return *c.Chain.MaxBatchGas
+}
+
+func (c *TOMLConfig) TxStorePath() string {
+	return *c.Chain.TxStorePath
+}
+
+func (c *TOMLConfig) TxStoreRetention() time.Duration {
+	return c.Chain.TxStoreRetention.Duration()
+}
+
+func (c *TOMLConfig) L1GasAmountPercent() uint32 {
+	return *c.Chain.L1GasAmountPercent
+}
+
+func (c *TOMLConfig) L1GasPricePercent() uint32 {
+	return *c.Chain.L1GasPricePercent
+}
+
+func (c *TOMLConfig) L2GasAmountPercent() uint32 {
+	return *c.Chain.L2GasAmountPercent
+}
+
+func (c *TOMLConfig) L2GasPricePercent() uint32 {
+	return *c.Chain.L2GasPricePercent
+}
+
+func (c *TOMLConfig) L1GasMaxPrice() uint64 {
+	return *c.Chain.L1GasMaxPrice
+}
+
+func (c *TOMLConfig) L2GasMaxPrice() uint64 {
+	return *c.Chain.L2GasMaxPrice
+}
+
+func (c *TOMLConfig) MaxFee() uint64 {
+	return *c.Chain.MaxFee
+}
+
+func (c *TOMLConfig) TipStrategy() string {
+	return *c.Chain.TipStrategy
+}
+
+func (c *TOMLConfig) Tip() uint64 {
+	return *c.Chain.Tip
+}
+
+func (c *TOMLConfig) NonceDAMode() starknetrpc.DataAvailabilityMode {
+	return starknetrpc.DataAvailabilityMode(*c.Chain.NonceDAMode)
+}
+
+func (c *TOMLConfig) FeeDAMode() starknetrpc.DataAvailabilityMode {
+	return starknetrpc.DataAvailabilityMode(*c.Chain.FeeDAMode)
+}
+
+func (c *TOMLConfig) SimulateTxs() bool {
+	return *c.Chain.SimulateTxs
+}
+
+func (c *TOMLConfig) DryRun() bool {
+	return *c.Chain.DryRun
+}
+
+func (c *TOMLConfig) FinalityTarget() starknetrpc.TxnStatus {
+	return starknetrpc.TxnStatus(*c.Chain.FinalityTarget)
+}
+
+func (c *TOMLConfig) AccountClassHash() *felt.Felt {
+	return optionalFelt(*c.Chain.AccountClassHash)
+}
+
+func (c *TOMLConfig) AccountSalt() *felt.Felt {
+	return optionalFelt(*c.Chain.AccountSalt)
+}
+
+func (c *TOMLConfig) PaymasterAddress() *felt.Felt {
+	return optionalFelt(*c.Chain.PaymasterAddress)
+}
+
+func (c *TOMLConfig) PaymasterData() []*felt.Felt {
+	data := make([]*felt.Felt, 0, len(c.Chain.PaymasterData))
+	for _, d := range c.Chain.PaymasterData {
+		if f := optionalFelt(d); f != nil {
+			data = append(data, f)
+		}
+	}
+	return data
+}
+
+// RemoteSignerURL is the base URL of the remote signer, nil if keys are held by the node keystore
+func (c *TOMLConfig) RemoteSignerURL() *url.URL {
+	return (*url.URL)(c.Chain.RemoteSignerURL)
+}
+
+func (c *TOMLConfig) RemoteSignerTimeout() time.Duration {
+	return c.Chain.RemoteSignerTimeout.Duration()
+}
+
+// FeeTokenAddress is the ERC20 token sent by chain transfers, nil if transfers are disabled
+func (c *TOMLConfig) FeeTokenAddress() *felt.Felt {
+	return optionalFelt(*c.Chain.FeeTokenAddress)
+}
+
+func (c *TOMLConfig) BalancePollInterval() time.Duration {
+	return c.Chain.BalancePollInterval.Duration()
+}
+
+func (c *TOMLConfig) MinBalance() *big.Int {
+	b, ok := new(big.Int).SetString(*c.Chain.MinBalance, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return b
+}
+
+func (c *TOMLConfig) BalanceGating() bool {
+	return *c.Chain.BalanceGating
+}
+
+func (c *TOMLConfig) QueueSaturationPercent() uint32 {
+	return *c.Chain.QueueSaturationPercent
+}
+
+func (c *TOMLConfig) MaxUnconfirmedAge() time.Duration {
+	return c.Chain.MaxUnconfirmedAge.Duration()
+}
+
+func (c *TOMLConfig) MaxNonceErrors() uint32 {
+	return *c.Chain.MaxNonceErrors
+}
+
+// NodePollInterval is how often the nodes are probed and re-ranked, 0 disables probing so nodes are only marked unhealthy by failed requests
+func (c *TOMLConfig) NodePollInterval() time.Duration {
+	return c.Chain.NodePollInterval.Duration()
+}
+
+// NodeMaxSyncLag is how many blocks a node can be behind the highest node before it is reported unhealthy, 0 disables the check
+func (c *TOMLConfig) NodeMaxSyncLag() uint64 {
+	return *c.Chain.NodeMaxSyncLag
+}
+
+// RetryConfig is the retry policy of each method class of the client and the circuit breaker of each node
+func (c *TOMLConfig) RetryConfig() starknet.RetryConfig {
+	policy := func(retries *uint32) starknet.RetryPolicy {
+		return starknet.RetryPolicy{
+			MaxRetries:     *retries,
+			InitialBackoff: c.Chain.RetryInitialBackoff.Duration(),
+			MaxBackoff:     c.Chain.RetryMaxBackoff.Duration(),
+		}
+	}
+	return starknet.RetryConfig{
+		Read:             policy(c.Chain.ReadMaxRetries),
+		Write:            policy(c.Chain.WriteMaxRetries),
+		Simulate:         policy(c.Chain.SimulateMaxRetries),
+		BreakerThreshold: *c.Chain.CircuitBreakerThreshold,
+		BreakerCooldown:  c.Chain.CircuitBreakerCooldown.Duration(),
+	}
+}
+
+// optionalFelt parses a validated felt, nil if empty
+func optionalFelt(s string) *felt.Felt {
+	if s == "" {
+		return nil
+	}
+	f, err := starknetutils.HexToFelt(s)
+	if err != nil {
+		return nil
+	}
+	return f
+}
+
 func (c *TOMLConfig) OCR2CachePollPeriod() time.Duration {
 	return c.Chain.OCR2CachePollPeriod.Duration()
 }
@@ -24,7 +24,7 @@ const BLOCK_OUTPUT = `{"result": {"events": [ {"from_address": "0xd43963a4e875a3
 const ocr2ContractAddress = "0xd43963a4e875a361f5d164b2e70953598eb4f45fde86924082d51b4d78e489" // matches BLOCK_OUTPUT event
 
 func TestOCR2Client(t *testing.T) {
-	chainID := "SN_GOERLI"
+	chainID := "SN_MAIN" // served by the mock node
 	lggr := logger.Test(t)
 
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -49,6 +49,8 @@ func TestOCR2Client(t *testing.T) {
 			switch call.Method {
 			case "starknet_chainId":
 				out = []byte(`{"result":"0x534e5f4d41494e"}`)
+			case "starknet_specVersion":
+				out = []byte(`{"result":"0.6.0"}`)
 			case "starknet_call":
 				raw := call.Params[0]
 				reqdata := Request{}
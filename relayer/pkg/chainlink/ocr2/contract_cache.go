@@ -6,12 +6,15 @@ import (
 	"sync"
 	"time"
 
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
 	"github.com/pkg/errors"
 
 	"github.com/smartcontractkit/libocr/offchainreporting2/types"
 
 	"github.com/smartcontractkit/chainlink-common/pkg/logger"
 	"github.com/smartcontractkit/chainlink-common/pkg/utils"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
 )
 
 type Tracker interface {
@@ -30,19 +33,24 @@ type contractCache struct {
 	ccLastCheckedAt time.Time
 
 	stop, done chan struct{}
+	notify     chan struct{}
 
 	reader Reader
+	events starknet.SubscribeFunc[starknetrpc.EmittedEvent] // ConfigSet events, nil if the reader can't subscribe
 	cfg    Config
 	lggr   logger.Logger
 }
 
-func NewContractCache(cfg Config, reader Reader, lggr logger.Logger) *contractCache {
+// NewContractCache returns a cache that polls the contract config, and updates it as soon as ConfigSet events are pushed if events is not nil
+func NewContractCache(cfg Config, reader Reader, events starknet.SubscribeFunc[starknetrpc.EmittedEvent], lggr logger.Logger) *contractCache {
 	return &contractCache{
 		cfg:    cfg,
 		reader: reader,
+		events: events,
 		lggr:   lggr,
 		stop:   make(chan struct{}),
 		done:   make(chan struct{}),
+		notify: make(chan struct{}, 1),
 	}
 }
 
@@ -102,11 +110,25 @@ func (c *contractCache) Close() error {
 
 func (c *contractCache) poll() {
 	defer close(c.done)
+	watchCtx, cancelWatch := utils.ContextFromChan(c.stop)
+	defer cancelWatch()
+	pushed := watchEvents(watchCtx, c.lggr, c.events)
 	tick := time.After(0)
 	for {
 		select {
 		case <-c.stop:
 			return
+		case <-pushed:
+			ctx, cancel := utils.ContextFromChan(c.stop)
+			if err := c.updateConfig(ctx); err != nil {
+				c.lggr.Errorf("Failed to update config after ConfigSet event: %v", err)
+			} else {
+				select {
+				case c.notify <- struct{}{}:
+				default:
+				}
+			}
+			cancel()
 		case <-tick:
 			ctx, cancel := utils.ContextFromChan(c.stop)
 
@@ -120,8 +142,12 @@ func (c *contractCache) poll() {
 	}
 }
 
+// Notify fires after pushed ConfigSet events updated the config, it is nil without subscriptions so that only polling is used
 func (c *contractCache) Notify() <-chan struct{} {
-	return nil
+	if c.events == nil {
+		return nil
+	}
+	return c.notify
 }
 
 func (c *contractCache) LatestConfigDetails(ctx context.Context) (changedInBlock uint64, configDigest types.ConfigDigest, err error) {
@@ -96,11 +96,13 @@ func (c *contractTransmitter) Transmit(
 		return err
 	}
 
-	err = c.txm.Enqueue(c.accountAddress, c.senderAddress, starknetrpc.FunctionCall{
+	call := starknetrpc.FunctionCall{
 		ContractAddress:    c.contractAddress,
 		EntryPointSelector: starknetutils.GetSelectorFromNameFelt("transmit"),
 		Calldata:           calldata,
-	})
+	}
+	// a newer report makes queued ones stale, they would revert on chain
+	_, err = c.txm.Enqueue(c.accountAddress, c.senderAddress, call, txm.TxOpts{DedupKey: txm.CallKey(call)})
 
 	return err
 }
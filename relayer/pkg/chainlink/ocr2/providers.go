@@ -37,7 +37,11 @@ func NewConfigProvider(chainID string, contractAddress string, basereader starkn
 	}
 
 	reader := NewContractReader(contractAddress, chainReader, lggr)
-	cache := NewContractCache(cfg, reader, lggr)
+	events, err := contractEvents(basereader, contractAddress, ConfigSetEventSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "err in NewConfigProvider.contractEvents")
+	}
+	cache := NewContractCache(cfg, reader, events, lggr)
 	digester := NewOffchainConfigDigester(chainID, contractAddress)
 
 	return &configProvider{
@@ -94,7 +98,11 @@ func NewMedianProvider(chainID string, contractAddress string, senderAddress str
 		return nil, errors.Wrap(err, "error in NewMedianProvider.NewConfigProvider")
 	}
 
-	cache := NewTransmissionsCache(cfg, configProvider.reader, lggr)
+	events, err := contractEvents(basereader, contractAddress, NewTransmissionEventSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "error in NewMedianProvider.contractEvents")
+	}
+	cache := NewTransmissionsCache(cfg, configProvider.reader, events, lggr)
 	transmitter := NewContractTransmitter(cache, contractAddress, senderAddress, accountAddress, txm)
 
 	return &medianProvider{
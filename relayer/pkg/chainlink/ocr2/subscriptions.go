@@ -0,0 +1,52 @@
+package ocr2
+
+import (
+	"context"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	starknetutils "github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+// contractEvents returns a function subscribing to the events of the contract with the selector,
+// nil if the reader can't subscribe
+func contractEvents(reader starknet.Reader, address string, selector string) (starknet.SubscribeFunc[starknetrpc.EmittedEvent], error) {
+	subscriber, ok := reader.(starknet.Subscriber)
+	if !ok {
+		return nil, nil
+	}
+	if client, ok := reader.(*starknet.Client); ok && !client.SubscriptionsEnabled() {
+		return nil, nil
+	}
+	contract, err := starknetutils.HexToFelt(address)
+	if err != nil {
+		return nil, err
+	}
+	key, err := starknetutils.HexToFelt("0x" + selector)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) (*starknet.Subscription[starknetrpc.EmittedEvent], error) {
+		return subscriber.SubscribeEvents(ctx, contract, [][]*felt.Felt{{key}})
+	}, nil
+}
+
+// watchEvents signals the returned channel when events are pushed, until ctx is done.
+// Pushes are coalesced while the signal is pending, and the channel never fires if events is nil.
+func watchEvents(ctx context.Context, lggr logger.Logger, events starknet.SubscribeFunc[starknetrpc.EmittedEvent]) <-chan struct{} {
+	pushed := make(chan struct{}, 1)
+	if events == nil {
+		return pushed
+	}
+	go starknet.Watch(ctx, lggr, events, func(starknetrpc.EmittedEvent) {
+		select {
+		case pushed <- struct{}{}:
+		default:
+		}
+	})
+	return pushed
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
 	"github.com/pkg/errors"
 
 	"github.com/smartcontractkit/libocr/offchainreporting2/reportingplugin/median"
@@ -14,6 +15,8 @@ import (
 
 	"github.com/smartcontractkit/chainlink-common/pkg/logger"
 	"github.com/smartcontractkit/chainlink-common/pkg/utils"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
 )
 
 var _ Tracker = (*transmissionsCache)(nil)
@@ -27,14 +30,17 @@ type transmissionsCache struct {
 	stop, done chan struct{}
 
 	reader Reader
+	events starknet.SubscribeFunc[starknetrpc.EmittedEvent] // NewTransmission events, nil if the reader can't subscribe
 	cfg    Config
 	lggr   logger.Logger
 }
 
-func NewTransmissionsCache(cfg Config, reader Reader, lggr logger.Logger) *transmissionsCache {
+// NewTransmissionsCache returns a cache that polls the latest transmission, and updates it as soon as NewTransmission events are pushed if events is not nil
+func NewTransmissionsCache(cfg Config, reader Reader, events starknet.SubscribeFunc[starknetrpc.EmittedEvent], lggr logger.Logger) *transmissionsCache {
 	return &transmissionsCache{
 		cfg:    cfg,
 		reader: reader,
+		events: events,
 		lggr:   lggr,
 		stop:   make(chan struct{}),
 		done:   make(chan struct{}),
@@ -83,11 +89,20 @@ func (c *transmissionsCache) Close() error {
 
 func (c *transmissionsCache) poll() {
 	defer close(c.done)
+	watchCtx, cancelWatch := utils.ContextFromChan(c.stop)
+	defer cancelWatch()
+	pushed := watchEvents(watchCtx, c.lggr, c.events)
 	tick := time.After(0)
 	for {
 		select {
 		case <-c.stop:
 			return
+		case <-pushed:
+			ctx, cancel := utils.ContextFromChan(c.stop)
+			if err := c.updateTransmission(ctx); err != nil {
+				c.lggr.Errorf("Failed to update transmission after NewTransmission event: %v", err)
+			}
+			cancel()
 		case <-tick:
 			ctx, cancel := utils.ContextFromChan(c.stop)
 
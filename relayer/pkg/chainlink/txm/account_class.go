@@ -0,0 +1,139 @@
+package txm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+// ErrUnsupportedAccount is returned for accounts that can't send V3 txs, the only version sent by the TXM
+var ErrUnsupportedAccount = errors.New("unsupported account: Cairo 0 accounts don't support V3 txs")
+
+// AccountClass describes the class of a sender account, detected once when the account is first used
+type AccountClass struct {
+	ClassHash *felt.Felt
+	// CairoVersion selects the __execute__ calldata format, as used by [starknetaccount.Account.FmtCalldata]:
+	// 0 for Cairo 0 call arrays and 2 for serialized Cairo 1 calls
+	CairoVersion int
+	// SupportsV3 is false for Cairo 0 accounts, which predate V3 txs. The TXM refuses them.
+	SupportsV3 bool
+	// SupportsOutsideExecution is true if the account implements SNIP-9 execute_from_outside
+	SupportsOutsideExecution bool
+}
+
+// accountClass returns the cached class of the account, detecting it on first use. Accounts that are not deployed yet
+// are expected to be deployed with the configured account class hash. Accounts without V3 support fail with [ErrUnsupportedAccount].
+func (txm *starktxm) accountClass(ctx context.Context, provider starknetrpc.RpcProvider, address *felt.Felt) (AccountClass, error) {
+	txm.classesLock.RLock()
+	class, exists := txm.classes[address.String()]
+	txm.classesLock.RUnlock()
+	if exists {
+		return class, class.supported(address)
+	}
+
+	classHash, err := provider.ClassHashAt(ctx, starknetrpc.BlockID{Tag: "latest"}, address)
+	if err = starknet.ClassifyError(err); errors.Is(err, starknet.ErrContractNotDeployed) && txm.cfg.AccountClassHash() != nil {
+		classHash, err = txm.cfg.AccountClassHash(), nil
+	}
+	if err != nil {
+		return AccountClass{}, fmt.Errorf("failed to get class hash of account %s: %w", address, err)
+	}
+	class, err = detectAccountClass(ctx, provider, classHash)
+	if err != nil {
+		return AccountClass{}, fmt.Errorf("failed to detect class of account %s: %w", address, err)
+	}
+
+	txm.lggr.Infow("detected account class", "account", address, "classHash", classHash,
+		"cairoVersion", class.CairoVersion, "supportsV3", class.SupportsV3, "supportsOutsideExecution", class.SupportsOutsideExecution)
+
+	txm.classesLock.Lock()
+	defer txm.classesLock.Unlock()
+	txm.classes[address.String()] = class
+	return class, class.supported(address)
+}
+
+// supported returns [ErrUnsupportedAccount] if the account can't send V3 txs
+func (class AccountClass) supported(address *felt.Felt) error {
+	if !class.SupportsV3 {
+		return fmt.Errorf("%w: account %s has class %s, upgrade it to a Cairo 1 class", ErrUnsupportedAccount, address, class.ClassHash)
+	}
+	return nil
+}
+
+// detectAccountClass fetches the class and derives the account capabilities from its ABI
+func detectAccountClass(ctx context.Context, provider starknetrpc.RpcProvider, classHash *felt.Felt) (AccountClass, error) {
+	out, err := provider.Class(ctx, starknetrpc.BlockID{Tag: "latest"}, classHash)
+	if err != nil {
+		return AccountClass{}, fmt.Errorf("failed to get class %s: %w", classHash, starknet.ClassifyError(err))
+	}
+
+	class := AccountClass{ClassHash: classHash}
+	var functions map[string]bool
+	switch c := out.(type) {
+	case *starknetrpc.ContractClass:
+		class.CairoVersion = 2
+		class.SupportsV3 = true
+		if functions, err = sierraFunctions(c.ABI); err != nil {
+			return AccountClass{}, fmt.Errorf("failed to parse ABI of class %s: %w", classHash, err)
+		}
+	case *starknetrpc.DeprecatedContractClass:
+		class.CairoVersion = 0
+		functions = deprecatedFunctions(c.ABI)
+	default:
+		return AccountClass{}, fmt.Errorf("unexpected class type %T for class %s", out, classHash)
+	}
+
+	// the ABI is optional, so a class without one is trusted to be an account
+	if len(functions) > 0 && !functions["__execute__"] {
+		return AccountClass{}, fmt.Errorf("class %s is not an account: missing __execute__", classHash)
+	}
+	class.SupportsOutsideExecution = functions["execute_from_outside"] || functions["execute_from_outside_v2"]
+	return class, nil
+}
+
+// sierraABIEntry is the subset of a Cairo 1 ABI entry needed to find the functions, including those of interfaces
+type sierraABIEntry struct {
+	Type  string           `json:"type"`
+	Name  string           `json:"name"`
+	Items []sierraABIEntry `json:"items"`
+}
+
+// sierraFunctions returns the names of the functions in a Cairo 1 ABI
+func sierraFunctions(abi string) (map[string]bool, error) {
+	functions := map[string]bool{}
+	if abi == "" {
+		return functions, nil
+	}
+	var entries []sierraABIEntry
+	if err := json.Unmarshal([]byte(abi), &entries); err != nil {
+		return nil, err
+	}
+	for len(entries) > 0 {
+		entry := entries[0]
+		entries = append(entries[1:], entry.Items...)
+		if entry.Type == string(starknetrpc.ABITypeFunction) {
+			functions[entry.Name] = true
+		}
+	}
+	return functions, nil
+}
+
+// deprecatedFunctions returns the names of the functions in a Cairo 0 ABI
+func deprecatedFunctions(abi *starknetrpc.ABI) map[string]bool {
+	functions := map[string]bool{}
+	if abi == nil {
+		return functions
+	}
+	for _, entry := range *abi {
+		if fn, ok := entry.(*starknetrpc.FunctionABIEntry); ok && fn.Type == starknetrpc.ABITypeFunction {
+			functions[fn.Name] = true
+		}
+	}
+	return functions
+}
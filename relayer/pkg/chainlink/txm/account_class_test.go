@@ -0,0 +1,126 @@
+package txm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm/mocks"
+)
+
+// sierraClass returns a starknet_getClass response for a Cairo 1 class with the ABI
+func sierraClass(abi string) string {
+	encoded, _ := json.Marshal(abi)
+	return `{"jsonrpc": "2.0", "id": 1, "result": {"sierra_program": [], "contract_class_version": "0.1.0",
+		"entry_points_by_type": {"CONSTRUCTOR": [], "EXTERNAL": [], "L1_HANDLER": []}, "abi": ` + string(encoded) + `}}`
+}
+
+// deprecatedClass returns a starknet_getClass response for a Cairo 0 class with the ABI
+func deprecatedClass(abi string) string {
+	return `{"jsonrpc": "2.0", "id": 1, "result": {"program": "",
+		"entry_points_by_type": {"CONSTRUCTOR": [], "EXTERNAL": [], "L1_HANDLER": []}, "abi": ` + abi + `}}`
+}
+
+func TestStarkTxm_AccountClass(t *testing.T) {
+	t.Parallel()
+
+	classHashAt := `{"jsonrpc": "2.0", "id": 1, "result": "0xc1a55"}`
+	notDeployed := `{"jsonrpc": "2.0", "id": 1, "error": {"code": 20, "message": "Contract not found"}}`
+	classHash := new(felt.Felt).SetUint64(0xc1a55)
+
+	for _, tc := range []struct {
+		name      string
+		responses map[string]string
+		classHash *felt.Felt // configured account class hash
+		class     AccountClass
+		err       string
+	}{
+		{
+			name: "cairo 1",
+			responses: map[string]string{
+				"starknet_getClassHashAt": classHashAt,
+				"starknet_getClass": sierraClass(`[
+					{"type": "impl", "name": "AccountImpl", "interface_name": "IAccount"},
+					{"type": "interface", "name": "IAccount", "items": [{"type": "function", "name": "__execute__"}, {"type": "function", "name": "__validate__"}]},
+					{"type": "interface", "name": "IOutsideExecution", "items": [{"type": "function", "name": "execute_from_outside_v2"}]}
+				]`),
+			},
+			class: AccountClass{ClassHash: classHash, CairoVersion: 2, SupportsV3: true, SupportsOutsideExecution: true},
+		},
+		{
+			name: "cairo 0",
+			responses: map[string]string{
+				"starknet_getClassHashAt": classHashAt,
+				"starknet_getClass":       deprecatedClass(`[{"type": "function", "name": "__execute__", "inputs": [], "outputs": []}]`),
+			},
+			err: "unsupported account",
+		},
+		{
+			name: "not an account",
+			responses: map[string]string{
+				"starknet_getClassHashAt": classHashAt,
+				"starknet_getClass":       sierraClass(`[{"type": "function", "name": "transfer"}]`),
+			},
+			err: "is not an account",
+		},
+		{
+			name: "not deployed",
+			responses: map[string]string{
+				"starknet_getClassHashAt": notDeployed,
+				"starknet_getClass":       sierraClass(`[{"type": "function", "name": "__execute__"}]`),
+			},
+			classHash: classHash,
+			class:     AccountClass{ClassHash: classHash, CairoVersion: 2, SupportsV3: true},
+		},
+		{
+			name:      "not deployed without class hash",
+			responses: map[string]string{"starknet_getClassHashAt": notDeployed},
+			err:       "contract not deployed",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := mocks.NewConfig(t)
+			cfg.On("AccountClassHash").Return(tc.classHash).Maybe()
+			txm := &starktxm{lggr: logger.Test(t), cfg: cfg, classes: map[string]AccountClass{}}
+			address := new(felt.Felt).SetUint64(1)
+
+			class, err := txm.accountClass(context.Background(), newTestProvider(t, tc.responses), address)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.class, class)
+
+			// the class is only detected once
+			class, err = txm.accountClass(context.Background(), newTestProvider(t, nil), address)
+			require.NoError(t, err)
+			assert.Equal(t, tc.class, class)
+		})
+	}
+}
+
+func TestStarkTxm_AccountClass_Cairo0(t *testing.T) {
+	t.Parallel()
+
+	txm := &starktxm{lggr: logger.Test(t), cfg: mocks.NewConfig(t), classes: map[string]AccountClass{}}
+	address := new(felt.Felt).SetUint64(1)
+	responses := map[string]string{
+		"starknet_getClassHashAt": `{"jsonrpc": "2.0", "id": 1, "result": "0xc1a55"}`,
+		"starknet_getClass":       deprecatedClass(`[{"type": "function", "name": "__execute__", "inputs": [], "outputs": []}]`),
+	}
+
+	// Cairo 0 accounts are refused at registration, also once their class is cached
+	_, err := txm.accountClass(context.Background(), newTestProvider(t, responses), address)
+	require.ErrorIs(t, err, ErrUnsupportedAccount)
+	_, err = txm.accountClass(context.Background(), newTestProvider(t, nil), address)
+	require.ErrorIs(t, err, ErrUnsupportedAccount)
+}
@@ -0,0 +1,116 @@
+package txm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"golang.org/x/exp/maps"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/utils"
+)
+
+// ErrLowBalance is returned for accounts whose fee token balance is below the configured minimum
+var ErrLowBalance = errors.New("account balance below minimum")
+
+// monitorBalances returns true if the balance monitor is enabled
+func (txm *starktxm) monitorBalances() bool {
+	return txm.cfg.BalancePollInterval() > 0 && txm.cfg.FeeTokenAddress() != nil
+}
+
+// balanceLoop periodically reads the fee token balance of every account that enqueued txs
+func (txm *starktxm) balanceLoop() {
+	defer txm.done.Done()
+
+	ctx, cancel := utils.ContextFromChan(txm.stop)
+	defer cancel()
+
+	txm.lggr.Debugw("balanceLoop: started")
+	txm.checkBalances(ctx)
+	for {
+		select {
+		case <-time.After(utils.WithJitter(txm.cfg.BalancePollInterval())):
+			txm.checkBalances(ctx)
+		case <-txm.stop:
+			txm.lggr.Debugw("balanceLoop: stopped")
+			return
+		}
+	}
+}
+
+// checkBalances records the current balance of each account, balances that fail to load keep their last value
+func (txm *starktxm) checkBalances(ctx context.Context) {
+	client, err := txm.client.Get()
+	if err != nil {
+		txm.client.Reset()
+		txm.lggr.Errorw("failed to fetch client: skipping balance check", "error", err)
+		return
+	}
+	token, minBalance := txm.cfg.FeeTokenAddress(), txm.cfg.MinBalance()
+
+	txm.accountsLock.RLock()
+	accounts := maps.Values(txm.accounts)
+	txm.accountsLock.RUnlock()
+
+	for _, acc := range accounts {
+		balance, err := client.BalanceOf(ctx, token, acc.address)
+		if err != nil {
+			txm.lggr.Errorw("failed to get account balance", "account", acc.address, "token", token, "error", err)
+			continue
+		}
+		fbalance, _ := new(big.Float).SetInt(balance).Float64()
+		promAccountBalance.WithLabelValues(acc.chainID, acc.address.String(), token.String()).Set(fbalance)
+		if balance.Cmp(minBalance) < 0 {
+			txm.lggr.Warnw("account balance below minimum", "account", acc.address, "balance", balance, "minBalance", minBalance, "token", token)
+		}
+
+		txm.balancesLock.Lock()
+		txm.balances[acc.address.String()] = balance
+		txm.balancesLock.Unlock()
+	}
+}
+
+// lowBalance returns [ErrLowBalance] if the last read balance of the account is below the minimum, nil if it is unknown
+func (txm *starktxm) lowBalance(address *felt.Felt) error {
+	txm.balancesLock.RLock()
+	balance, exists := txm.balances[address.String()]
+	txm.balancesLock.RUnlock()
+	if !exists {
+		return nil
+	}
+	return checkMinBalance(address.String(), balance, txm.cfg.MinBalance())
+}
+
+// gated returns [ErrLowBalance] if txs from the account are held back because of balance gating
+func (txm *starktxm) gated(address *felt.Felt) error {
+	if err := txm.lowBalance(address); err != nil && txm.cfg.BalanceGating() {
+		return err
+	}
+	return nil
+}
+
+// balanceHealth joins the errors of all accounts below the minimum balance
+func (txm *starktxm) balanceHealth() error {
+	minBalance := txm.cfg.MinBalance()
+	txm.balancesLock.RLock()
+	defer txm.balancesLock.RUnlock()
+
+	addresses := maps.Keys(txm.balances)
+	slices.Sort(addresses)
+	var errs []error
+	for _, address := range addresses {
+		errs = append(errs, checkMinBalance(address, txm.balances[address], minBalance))
+	}
+	return errors.Join(errs...)
+}
+
+func checkMinBalance(address string, balance, minBalance *big.Int) error {
+	if balance.Cmp(minBalance) < 0 {
+		return fmt.Errorf("%w: %s has %s < %s", ErrLowBalance, address, balance, minBalance)
+	}
+	return nil
+}
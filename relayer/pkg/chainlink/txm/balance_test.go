@@ -0,0 +1,79 @@
+package txm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+	"github.com/smartcontractkit/chainlink-common/pkg/utils"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm/mocks"
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+func TestStarkTxm_CheckBalances(t *testing.T) {
+	t.Parallel()
+
+	// balanceOf returns a u256 of 5 FRI
+	provider := newTestProvider(t, map[string]string{
+		"starknet_call": `{"jsonrpc": "2.0", "id": 1, "result": ["0x5", "0x0"]}`,
+	})
+	client := &starknet.Client{Provider: provider}
+	address := new(felt.Felt).SetUint64(0xba1)
+	token := new(felt.Felt).SetUint64(0x70c)
+
+	for _, tc := range []struct {
+		name       string
+		minBalance int64
+		gating     bool
+		low        bool
+	}{
+		{name: "funded", minBalance: 5},
+		{name: "low", minBalance: 6, low: true},
+		{name: "low and gated", minBalance: 6, gating: true, low: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := mocks.NewConfig(t)
+			cfg.On("FeeTokenAddress").Return(token)
+			cfg.On("MinBalance").Return(big.NewInt(tc.minBalance))
+			cfg.On("BalanceGating").Return(tc.gating).Maybe()
+
+			chainID := "SN_BALANCE_" + tc.name
+			txm := &starktxm{
+				lggr:     logger.Test(t),
+				cfg:      cfg,
+				client:   utils.NewLazyLoad(func() (*starknet.Client, error) { return client, nil }),
+				accounts: map[string]*txAccount{address.String(): newTxAccount(chainID, address, &felt.Zero)},
+				balances: map[string]*big.Int{},
+			}
+
+			// unknown balances are not gated
+			require.NoError(t, txm.lowBalance(address))
+
+			txm.checkBalances(context.Background())
+			assert.Equal(t, 5.0, testutil.ToFloat64(promAccountBalance.WithLabelValues(chainID, address.String(), token.String())))
+
+			if !tc.low {
+				assert.NoError(t, txm.lowBalance(address))
+				assert.NoError(t, txm.gated(address))
+				assert.NoError(t, txm.balanceHealth())
+				return
+			}
+			assert.ErrorIs(t, txm.lowBalance(address), ErrLowBalance)
+			assert.ErrorIs(t, txm.balanceHealth(), ErrLowBalance)
+			if tc.gating {
+				assert.ErrorIs(t, txm.gated(address), ErrLowBalance)
+			} else {
+				assert.NoError(t, txm.gated(address))
+			}
+		})
+	}
+}
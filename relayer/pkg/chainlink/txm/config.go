@@ -1,6 +1,12 @@
 package txm
 
-import "time"
+import (
+	"math/big"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+)
 
 //go:generate mockery --name Config --output ./mocks/ --case=underscore --filename config.go
 
@@ -8,4 +14,66 @@ import "time"
 type Config interface {
 	ConfirmationPoll() time.Duration
 	TxTimeout() time.Duration
+	// StuckTxTimeout is how long a tx attempt can stay unconfirmed before it is resubmitted with higher fees, 0 disables resubmission
+	StuckTxTimeout() time.Duration
+	// NonceSyncInterval is how often local nonces are compared with the on-chain nonce
+	NonceSyncInterval() time.Duration
+	// BatchWindow is how long calls for the same account are collected into a single multicall tx, 0 disables batching
+	BatchWindow() time.Duration
+	// MaxBatchSize is the maximum number of calls in a multicall tx
+	MaxBatchSize() int
+	// MaxBatchGas is the maximum estimated gas of a multicall tx, larger batches are split. 0 disables the limit
+	MaxBatchGas() uint64
+	// L1GasAmountPercent and L1GasPricePercent scale the fee estimate into the L1 gas bounds, 0 leaves the bound at 0
+	L1GasAmountPercent() uint32
+	L1GasPricePercent() uint32
+	// L2GasAmountPercent and L2GasPricePercent scale the fee estimate into the L2 gas bounds, 0 leaves the bound at 0
+	L2GasAmountPercent() uint32
+	L2GasPricePercent() uint32
+	// L1GasMaxPrice is the max price per unit of L1 gas (in FRI), 0 is uncapped
+	L1GasMaxPrice() uint64
+	// L2GasMaxPrice is the max price per unit of L2 gas (in FRI), 0 is uncapped
+	L2GasMaxPrice() uint64
+	// MaxFee is the max total fee of a tx (in FRI), 0 is unlimited
+	MaxFee() uint64
+	// TipStrategy is one of "none", "fixed" or "percent"
+	TipStrategy() string
+	// Tip is the fixed tip, or the percent of the gas price for the "percent" strategy
+	Tip() uint64
+	// NonceDAMode is the data availability mode of the nonce, "L1" or "L2"
+	NonceDAMode() starknetrpc.DataAvailabilityMode
+	// FeeDAMode is the data availability mode of the fee, "L1" or "L2"
+	FeeDAMode() starknetrpc.DataAvailabilityMode
+	// SimulateTxs enables simulating txs before they are submitted, txs that revert are dropped
+	SimulateTxs() bool
+	// DryRun signs and simulates txs without submitting them
+	DryRun() bool
+	// FinalityTarget is the finality status at which included txs are finalized, ACCEPTED_ON_L2 or ACCEPTED_ON_L1.
+	// Txs free their nonce once included on L2 either way, an L1 target keeps tracking them until they settle.
+	FinalityTarget() starknetrpc.TxnStatus
+	// AccountClassHash and AccountSalt are used to deploy accounts that enqueue txs before they are deployed, nil disables deploys
+	AccountClassHash() *felt.Felt
+	AccountSalt() *felt.Felt
+	// PaymasterAddress is the SNIP-8 paymaster that sponsors all txs, nil if senders pay their own fees
+	PaymasterAddress() *felt.Felt
+	// PaymasterData is passed to the paymaster after its address
+	PaymasterData() []*felt.Felt
+	// FeeTokenAddress is the ERC20 token the fees are paid in, nil disables balance monitoring
+	FeeTokenAddress() *felt.Felt
+	// BalancePollInterval is how often the fee token balance of accounts that enqueued txs is read, 0 disables monitoring
+	BalancePollInterval() time.Duration
+	// MinBalance is the fee token balance (in the smallest unit of the token) below which an account is reported unhealthy
+	MinBalance() *big.Int
+	// BalanceGating holds back txs from accounts below the MinBalance instead of only reporting them
+	BalanceGating() bool
+	// QueueSaturationPercent is the percent of MaxQueueLen queued txs at which an account is reported unhealthy, 0 disables the check
+	QueueSaturationPercent() uint32
+	// MaxUnconfirmedAge is how long a broadcast tx can stay unconfirmed before it is reported unhealthy, 0 disables the check
+	MaxUnconfirmedAge() time.Duration
+	// MaxNonceErrors is the number of consecutive nonce errors after which an account is reported unhealthy, 0 disables the check
+	MaxNonceErrors() uint32
+	// TxStorePath is the file used to persist txs across restarts, txs are only kept in memory if empty. Required on mainnet
+	TxStorePath() string
+	// TxStoreRetention is how long terminal txs are kept once they reached the finality target before being pruned
+	TxStoreRetention() time.Duration
 }
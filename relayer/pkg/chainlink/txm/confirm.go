@@ -0,0 +1,141 @@
+package txm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	starknetutils "github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+// checkConfirmation fetches the receipt of an unconfirmed tx and records its outcome once it was included or rejected
+func (txm *starktxm) checkConfirmation(ctx context.Context, client *starknet.Client, chainID string, addr *felt.Felt, hash string) {
+	f, err := starknetutils.HexToFelt(hash)
+	if err != nil {
+		txm.lggr.Errorw("invalid felt value", "hash", hash)
+		return
+	}
+	outcome, done, err := fetchOutcome(ctx, client.Provider, f)
+	if err != nil {
+		txm.lggr.Errorw("failed to fetch transaction outcome", "hash", hash, "error", err)
+		return
+	}
+	if !done {
+		return
+	}
+
+	if err := txm.txStore.Confirm(addr, hash, outcome); err != nil {
+		txm.lggr.Errorw("failed to confirm tx in TxStore", "hash", hash, "sender", addr, "error", err)
+		return
+	}
+	status := outcome.Status()
+	promTxConfirmed.WithLabelValues(chainID, addr.String(), string(status)).Inc()
+	rec, err := txm.txStore.Get(hash)
+	if err != nil {
+		txm.lggr.Errorw("failed to get tx from TxStore", "hash", hash, "error", err)
+	} else if status != TxStatusRejected {
+		promInclusionDuration.WithLabelValues(chainID, addr.String()).Observe(time.Since(rec.CreatedAt).Seconds())
+		contract := calledContract(rec.Calls)
+		if rec.Type == starknetrpc.TransactionType_DeployAccount {
+			contract = addr.String()
+		}
+		if outcome.ActualFee != nil {
+			promFeesPaid.WithLabelValues(chainID, addr.String(), contract).Add(feltToFloat(outcome.ActualFee))
+		}
+	}
+
+	lggr := logger.With(txm.lggr, "hash", hash, "sender", addr)
+	switch status {
+	case TxStatusRejected:
+		// the nonce was not used, later txs can't be included until the local nonce is reset
+		lggr.Warnw(fmt.Sprintf("tx rejected: %s", outcome.FinalityStatus))
+		if err := txm.Resync(ctx, addr); err != nil {
+			lggr.Errorw("failed to resync nonce", "error", err)
+		}
+	case TxStatusReverted:
+		lggr.Warnw(fmt.Sprintf("tx reverted: %s", outcome.FinalityStatus), "revertReason", outcome.RevertReason, "actualFee", outcome.ActualFee, "blockNumber", outcome.BlockNumber)
+	default:
+		lggr.Infow(fmt.Sprintf("tx confirmed: %s", outcome.FinalityStatus), "actualFee", outcome.ActualFee, "blockNumber", outcome.BlockNumber)
+	}
+
+	txm.notifyStatus(rec.IDs...)
+}
+
+// checkFinality follows included txs until they are accepted on L1, if that is the finality target
+func (txm *starktxm) checkFinality(ctx context.Context, client *starknet.Client) {
+	if txm.cfg.FinalityTarget() != starknetrpc.TxnStatus_Accepted_On_L1 {
+		return
+	}
+	records, err := txm.txStore.GetUnfinalized()
+	if err != nil {
+		txm.lggr.Errorw("failed to fetch unfinalized txs", "error", err)
+		return
+	}
+	for _, rec := range records {
+		f, err := starknetutils.HexToFelt(rec.Hash)
+		if err != nil {
+			txm.lggr.Errorw("invalid felt value", "hash", rec.Hash)
+			continue
+		}
+		status, err := client.Provider.GetTransactionStatus(ctx, f)
+		if err != nil {
+			txm.lggr.Errorw("failed to fetch transaction status", "hash", rec.Hash, "error", err)
+			continue
+		}
+		if status.FinalityStatus != starknetrpc.TxnStatus_Accepted_On_L1 {
+			continue
+		}
+		if err := txm.txStore.SetFinality(rec.Hash, status.FinalityStatus); err != nil {
+			txm.lggr.Errorw("failed to finalize tx in TxStore", "hash", rec.Hash, "sender", rec.AccountAddress, "error", err)
+			continue
+		}
+		txm.lggr.Infow(fmt.Sprintf("tx finalized: %s", status.FinalityStatus), "hash", rec.Hash, "sender", rec.AccountAddress, "blockNumber", rec.BlockNumber)
+		txm.notifyStatus(rec.IDs...)
+	}
+}
+
+// fetchOutcome returns the outcome of a tx from its receipt, false if the tx is still pending
+func fetchOutcome(ctx context.Context, provider starknetrpc.RpcProvider, hash *felt.Felt) (TxOutcome, bool, error) {
+	receipt, rerr := provider.TransactionReceipt(ctx, hash)
+	if rerr == nil {
+		outcome, ok := receiptOutcome(receipt)
+		return outcome, ok, nil
+	}
+
+	// there is no receipt until the tx is included, the status shows if it was rejected instead
+	status, err := provider.GetTransactionStatus(ctx, hash)
+	if err != nil {
+		return TxOutcome{}, false, errors.Join(rerr, err)
+	}
+	if status.FinalityStatus == starknetrpc.TxnStatus_Rejected {
+		return TxOutcome{FinalityStatus: status.FinalityStatus}, true, nil
+	}
+	return TxOutcome{}, false, nil
+}
+
+// receiptOutcome returns the outcome recorded in the receipt of an included tx, false for pending receipts
+func receiptOutcome(receipt starknetrpc.TransactionReceipt) (TxOutcome, bool) {
+	var common starknetrpc.CommonTransactionReceipt
+	switch r := receipt.(type) {
+	case starknetrpc.InvokeTransactionReceipt:
+		common = starknetrpc.CommonTransactionReceipt(r)
+	case starknetrpc.DeployAccountTransactionReceipt:
+		common = r.CommonTransactionReceipt
+	default:
+		return TxOutcome{}, false
+	}
+	return TxOutcome{
+		FinalityStatus:  starknetrpc.TxnStatus(common.FinalityStatus),
+		ExecutionStatus: common.ExecutionStatus,
+		ActualFee:       common.ActualFee.Amount,
+		RevertReason:    common.RevertReason,
+		BlockNumber:     common.BlockNumber,
+	}, true
+}
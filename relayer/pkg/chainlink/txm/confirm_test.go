@@ -0,0 +1,216 @@
+package txm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm/mocks"
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+// newTestProvider returns a provider backed by a server that replies to each RPC method with a fixed response body
+func newTestProvider(t *testing.T, responses map[string]string) *starknetrpc.Provider {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		body, ok := responses[req.Method]
+		if !ok {
+			body = `{"jsonrpc": "2.0", "id": 1, "error": {"code": -32601, "message": "Method not found"}}`
+		}
+		_, err := w.Write([]byte(body))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := ethrpc.DialContext(context.Background(), server.URL)
+	require.NoError(t, err)
+	return starknetrpc.NewProvider(c)
+}
+
+func TestFetchOutcome(t *testing.T) {
+	t.Parallel()
+
+	receipt := func(execution, reason string) string {
+		return `{"jsonrpc": "2.0", "id": 1, "result": {"type": "INVOKE", "transaction_hash": "0x1", "block_hash": "0x2", "block_number": 7,
+			"actual_fee": {"amount": "0x64", "unit": "FRI"}, "finality_status": "ACCEPTED_ON_L2", "execution_status": "` + execution + `", "revert_reason": "` + reason + `"}}`
+	}
+	notFound := `{"jsonrpc": "2.0", "id": 1, "error": {"code": 29, "message": "Transaction hash not found"}}`
+	status := func(finality string) string {
+		return `{"jsonrpc": "2.0", "id": 1, "result": {"finality_status": "` + finality + `"}}`
+	}
+
+	for _, tc := range []struct {
+		name      string
+		responses map[string]string
+		done      bool
+		status    TxStatus
+	}{
+		{
+			name:      "succeeded",
+			responses: map[string]string{"starknet_getTransactionReceipt": receipt("SUCCEEDED", "")},
+			done:      true,
+			status:    TxStatusConfirmed,
+		},
+		{
+			name:      "reverted",
+			responses: map[string]string{"starknet_getTransactionReceipt": receipt("REVERTED", "stale report")},
+			done:      true,
+			status:    TxStatusReverted,
+		},
+		{
+			name: "pending",
+			responses: map[string]string{"starknet_getTransactionReceipt": `{"jsonrpc": "2.0", "id": 1, "result": {"type": "INVOKE", "transaction_hash": "0x1",
+				"actual_fee": {"amount": "0x64", "unit": "FRI"}, "execution_status": "SUCCEEDED"}}`},
+		},
+		{
+			name:      "received",
+			responses: map[string]string{"starknet_getTransactionReceipt": notFound, "starknet_getTransactionStatus": status("RECEIVED")},
+		},
+		{
+			name:      "rejected",
+			responses: map[string]string{"starknet_getTransactionReceipt": notFound, "starknet_getTransactionStatus": status("REJECTED")},
+			done:      true,
+			status:    TxStatusRejected,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			outcome, done, err := fetchOutcome(context.Background(), newTestProvider(t, tc.responses), new(felt.Felt).SetUint64(1))
+			require.NoError(t, err)
+			require.Equal(t, tc.done, done)
+			if !done {
+				return
+			}
+			assert.Equal(t, tc.status, outcome.Status())
+			if tc.status != TxStatusRejected {
+				assert.Equal(t, new(felt.Felt).SetUint64(100), outcome.ActualFee)
+				assert.Equal(t, uint64(7), outcome.BlockNumber)
+			}
+			if tc.status == TxStatusReverted {
+				assert.Equal(t, "stale report", outcome.RevertReason)
+			}
+		})
+	}
+
+	_, _, err := fetchOutcome(context.Background(), newTestProvider(t, map[string]string{}), new(felt.Felt).SetUint64(1))
+	require.Error(t, err)
+}
+
+func TestStarkTxm_CheckConfirmation(t *testing.T) {
+	t.Parallel()
+
+	cfg := mocks.NewConfig(t)
+	cfg.On("FinalityTarget").Return(starknetrpc.TxnStatus_Accepted_On_L2)
+	txm := &starktxm{
+		lggr:    logger.Test(t),
+		cfg:     cfg,
+		txStore: NewChainTxStore(),
+		status:  newStatusTracker(),
+	}
+	chainID := "SN_CONFIRM_TEST"
+	from := new(felt.Felt).SetUint64(1)
+	contract := new(felt.Felt).SetUint64(2)
+	require.NoError(t, txm.txStore.Save(TxRecord{
+		Hash:           "0x1",
+		IDs:            []string{"id"},
+		AccountAddress: from,
+		Nonce:          new(felt.Felt),
+		Calls:          []starknetrpc.FunctionCall{{ContractAddress: contract}, {ContractAddress: contract}},
+	}))
+
+	provider := newTestProvider(t, map[string]string{"starknet_getTransactionReceipt": `{"jsonrpc": "2.0", "id": 1, "result": {"type": "INVOKE", "transaction_hash": "0x1", "block_hash": "0x2", "block_number": 7,
+		"actual_fee": {"amount": "0x64", "unit": "FRI"}, "finality_status": "ACCEPTED_ON_L2", "execution_status": "REVERTED", "revert_reason": "stale report"}}`})
+	txm.checkConfirmation(context.Background(), &starknet.Client{Provider: provider}, chainID, from, "0x1")
+
+	state, err := txm.GetTransactionStatus("id")
+	require.NoError(t, err)
+	assert.Equal(t, TxStatusReverted, state.Status)
+	assert.Equal(t, "stale report", state.RevertReason)
+	assert.Equal(t, 0, txm.txStore.GetAllInflightCount()[from])
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(promTxConfirmed.WithLabelValues(chainID, from.String(), string(TxStatusReverted))))
+	assert.Equal(t, float64(100), testutil.ToFloat64(promFeesPaid.WithLabelValues(chainID, from.String(), contract.String())))
+}
+
+func TestCalledContract(t *testing.T) {
+	t.Parallel()
+
+	a, b := new(felt.Felt).SetUint64(1), new(felt.Felt).SetUint64(2)
+	assert.Equal(t, "", calledContract(nil))
+	assert.Equal(t, a.String(), calledContract([]starknetrpc.FunctionCall{{ContractAddress: a}, {ContractAddress: a}}))
+	assert.Equal(t, multicallContract, calledContract([]starknetrpc.FunctionCall{{ContractAddress: a}, {ContractAddress: b}}))
+}
+
+func TestStarkTxm_CheckFinality(t *testing.T) {
+	t.Parallel()
+
+	cfg := mocks.NewConfig(t)
+	cfg.On("FinalityTarget").Return(starknetrpc.TxnStatus_Accepted_On_L1)
+	txm := &starktxm{
+		lggr:    logger.Test(t),
+		cfg:     cfg,
+		txStore: NewChainTxStore(),
+		status:  newStatusTracker(),
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go txm.status.dispatchLoop(stop)
+	from := new(felt.Felt).SetUint64(1)
+	require.NoError(t, txm.txStore.Save(TxRecord{Hash: "0xa", IDs: []string{"id"}, AccountAddress: from, Nonce: new(felt.Felt)}))
+	require.NoError(t, txm.txStore.Save(TxRecord{Hash: "0xb", IDs: []string{"rejected"}, AccountAddress: from, Nonce: new(felt.Felt).SetUint64(1)}))
+
+	updates := make(chan TxState, 2)
+	require.NoError(t, txm.OnStatusChange("id", func(state TxState) { updates <- state }))
+
+	// included txs free their nonce but are not final until accepted on L1
+	require.NoError(t, txm.txStore.Confirm(from, "0xa", TxOutcome{FinalityStatus: starknetrpc.TxnStatus_Accepted_On_L2, ExecutionStatus: starknetrpc.TxnExecutionStatusSUCCEEDED}))
+	require.NoError(t, txm.txStore.Confirm(from, "0xb", TxOutcome{FinalityStatus: starknetrpc.TxnStatus_Rejected}))
+	txm.notifyStatus("id", "rejected")
+	assert.Equal(t, 0, txm.txStore.GetAllInflightCount()[from])
+
+	unfinalized, err := txm.GetUnfinalizedTransactions()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(unfinalized))
+	assert.Equal(t, "id", unfinalized[0].ID)
+	assert.Equal(t, TxStatusConfirmed, unfinalized[0].Status)
+	assert.False(t, unfinalized[0].Finalized)
+
+	status := func(finality string) map[string]string {
+		return map[string]string{"starknet_getTransactionStatus": `{"jsonrpc": "2.0", "id": 1, "result": {"finality_status": "` + finality + `", "execution_status": "SUCCEEDED"}}`}
+	}
+	txm.checkFinality(context.Background(), &starknet.Client{Provider: newTestProvider(t, status("ACCEPTED_ON_L2"))})
+	unfinalized, err = txm.GetUnfinalizedTransactions()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(unfinalized))
+
+	txm.checkFinality(context.Background(), &starknet.Client{Provider: newTestProvider(t, status("ACCEPTED_ON_L1"))})
+	unfinalized, err = txm.GetUnfinalizedTransactions()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(unfinalized))
+
+	state, err := txm.GetTransactionStatus("id")
+	require.NoError(t, err)
+	assert.Equal(t, TxStatusConfirmed, state.Status)
+	assert.Equal(t, starknetrpc.TxnStatus_Accepted_On_L1, state.FinalityStatus)
+	assert.True(t, state.Finalized)
+
+	// callbacks run for inclusion and finality
+	assert.False(t, (<-updates).Finalized)
+	assert.True(t, (<-updates).Finalized)
+	assert.Equal(t, 0, len(txm.status.callbacks))
+}
@@ -0,0 +1,128 @@
+package txm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetaccount "github.com/NethermindEth/starknet.go/account"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+// deployAccount sends a DEPLOY_ACCOUNT tx for an account that is not deployed yet, using the configured class hash
+// and salt. The account is expected to take the public key as its only constructor argument, as the OpenZeppelin
+// account does, and must be funded before it is deployed. Nothing is sent if a deploy of the account is inflight.
+func (txm *starktxm) deployAccount(ctx context.Context, client *starknet.Client, chainID string, address, publicKey *felt.Felt) error {
+	classHash, salt := txm.cfg.AccountClassHash(), txm.cfg.AccountSalt()
+	if classHash == nil || salt == nil {
+		return fmt.Errorf("account %s is not deployed and no account class hash and salt are configured", address)
+	}
+
+	// concurrent enqueues of an undeployed account wait for the first deploy instead of sending their own
+	lock, _ := txm.deployLocks.LoadOrStore(address.String(), &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+	if txm.txStore.Deploying(address) {
+		return nil
+	}
+
+	account, err := txm.newAccount(ctx, client, address, publicKey)
+	if err != nil {
+		return err
+	}
+	calldata := []*felt.Felt{publicKey}
+	precomputed, err := account.PrecomputeAddress(&felt.Zero, salt, classHash, calldata)
+	if err != nil {
+		return fmt.Errorf("failed to compute account address: %w", err)
+	}
+	if !precomputed.Equal(address) {
+		return fmt.Errorf("configured account class hash and salt deploy to %s, not %s", precomputed, address)
+	}
+
+	unlock := txm.lockAccount(address)
+	defer unlock()
+
+	policy := NewFeePolicy(txm.cfg)
+	tx := starknetrpc.DeployAccountTxnV3{
+		Type:                starknetrpc.TransactionType_DeployAccount,
+		Version:             starknetrpc.TransactionV3,
+		Signature:           []*felt.Felt{},
+		Nonce:               &felt.Zero,
+		ContractAddressSalt: salt,
+		ConstructorCalldata: calldata,
+		ClassHash:           classHash,
+		ResourceBounds: starknetrpc.ResourceBoundsMapping{ // set from the fee estimate
+			L1Gas: starknetrpc.ResourceBounds{
+				MaxAmount:       "0x0",
+				MaxPricePerUnit: "0x0",
+			},
+			L2Gas: starknetrpc.ResourceBounds{
+				MaxAmount:       "0x0",
+				MaxPricePerUnit: "0x0",
+			},
+		},
+		Tip:           "0x0",
+		PayMasterData: []*felt.Felt{},
+		NonceDataMode: policy.NonceDAMode,
+		FeeMode:       policy.FeeDAMode,
+	}
+	tx.PayMasterData, tx.FeeMode, err = txm.sponsor(ctx, address, nil, tx.FeeMode)
+	if err != nil {
+		return err
+	}
+	if err := signDeployAccount(ctx, account, &tx); err != nil {
+		return err
+	}
+
+	estimate, err := txm.estimateFee(ctx, chainID, account, tx)
+	if err != nil {
+		return err
+	}
+	tx.ResourceBounds, tx.Tip, err = policy.ResourceBounds(estimate)
+	if err != nil {
+		return err
+	}
+	if err := signDeployAccount(ctx, account, &tx); err != nil {
+		return err
+	}
+
+	if txm.cfg.DryRun() {
+		txm.lggr.Infow("dry run: account deploy not submitted", "account", address, "classHash", classHash)
+		return nil
+	}
+
+	execCtx, execCancel := context.WithTimeout(ctx, txm.cfg.TxTimeout())
+	defer execCancel()
+	res, err := account.AddDeployAccountTransaction(execCtx, starknetrpc.BroadcastDeployAccountTxnV3{DeployAccountTxnV3: tx})
+	if err != nil {
+		return fmt.Errorf("failed to deploy account: %+w", starknet.ClassifyError(err))
+	}
+	if res == nil {
+		return errors.New("deploy account response and error are nil")
+	}
+
+	txm.lggr.Infow("account deploy broadcast", "txhash", res.TransactionHash, "account", address, "classHash", classHash)
+	return txm.txStore.Save(TxRecord{
+		Hash:           res.TransactionHash.String(),
+		Type:           starknetrpc.TransactionType_DeployAccount,
+		AccountAddress: address,
+		PublicKey:      publicKey,
+		Nonce:          tx.Nonce,
+		ResourceBounds: tx.ResourceBounds,
+		Tip:            tx.Tip,
+	})
+}
+
+// TODO: SignDeployAccountTransaction for V3 is missing so we do it by hand
+func signDeployAccount(ctx context.Context, account *starknetaccount.Account, tx *starknetrpc.DeployAccountTxnV3) error {
+	hash, err := account.TransactionHashDeployAccount(*tx, account.AccountAddress)
+	if err != nil {
+		return err
+	}
+	tx.Signature, err = account.Sign(ctx, hash)
+	return err
+}
@@ -0,0 +1,179 @@
+package txm
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetaccount "github.com/NethermindEth/starknet.go/account"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+	"github.com/smartcontractkit/chainlink-common/pkg/loop"
+	adapters "github.com/smartcontractkit/chainlink-common/pkg/loop/adapters/starknet"
+	"github.com/smartcontractkit/chainlink-common/pkg/utils"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm/mocks"
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+// fixedSigKeystore signs every hash with the same signature
+type fixedSigKeystore struct{}
+
+var _ loop.Keystore = fixedSigKeystore{}
+
+func (fixedSigKeystore) Sign(ctx context.Context, id string, data []byte) ([]byte, error) {
+	sig, err := adapters.SignatureFromBigInts(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		return nil, err
+	}
+	return sig.Bytes()
+}
+
+func (fixedSigKeystore) Accounts(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func TestStarkTxm_DeployAccount(t *testing.T) {
+	t.Parallel()
+
+	chainID := `{"jsonrpc": "2.0", "id": 1, "result": "0x534e5f474f45524c49"}`
+	provider := newTestProvider(t, map[string]string{
+		"starknet_chainId":                     chainID,
+		"starknet_getClassHashAt":              `{"jsonrpc": "2.0", "id": 1, "error": {"code": 20, "message": "Contract not found"}}`,
+		"starknet_getClass":                    sierraClass(`[{"type": "function", "name": "__execute__"}]`),
+		"starknet_estimateFee":                 `{"jsonrpc": "2.0", "id": 1, "result": [{"gas_consumed": "0x10", "gas_price": "0x5", "overall_fee": "0x50", "unit": "FRI"}]}`,
+		"starknet_addDeployAccountTransaction": `{"jsonrpc": "2.0", "id": 1, "result": {"transaction_hash": "0xdead", "contract_address": "0x1"}}`,
+	})
+	client := &starknet.Client{Provider: provider}
+
+	publicKey := new(felt.Felt).SetUint64(0xbeef)
+	classHash := new(felt.Felt).SetUint64(0xc1a55)
+	salt := new(felt.Felt).SetUint64(7)
+	account, err := starknetaccount.NewAccount(provider, &felt.Zero, publicKey.String(), nil, 2)
+	require.NoError(t, err)
+	address, err := account.PrecomputeAddress(&felt.Zero, salt, classHash, []*felt.Felt{publicKey})
+	require.NoError(t, err)
+
+	cfg := mocks.NewConfig(t)
+	cfg.On("AccountClassHash").Return(classHash)
+	cfg.On("AccountSalt").Return(salt)
+	cfg.On("L1GasAmountPercent").Return(uint32(0)).Maybe()
+	cfg.On("L1GasPricePercent").Return(uint32(0)).Maybe()
+	cfg.On("L2GasAmountPercent").Return(uint32(140)).Maybe()
+	cfg.On("L2GasPricePercent").Return(uint32(100)).Maybe()
+	cfg.On("L1GasMaxPrice").Return(uint64(0)).Maybe()
+	cfg.On("L2GasMaxPrice").Return(uint64(0)).Maybe()
+	cfg.On("MaxFee").Return(uint64(0)).Maybe()
+	cfg.On("TipStrategy").Return(string(TipNone)).Maybe()
+	cfg.On("Tip").Return(uint64(0)).Maybe()
+	cfg.On("NonceDAMode").Return(starknetrpc.DAModeL1).Maybe()
+	cfg.On("FeeDAMode").Return(starknetrpc.DAModeL1).Maybe()
+	cfg.On("DryRun").Return(false).Maybe()
+	cfg.On("TxTimeout").Return(time.Second).Maybe()
+
+	txm := &starktxm{
+		lggr:     logger.Test(t),
+		cfg:      cfg,
+		ks:       NewKeystoreAdapter(fixedSigKeystore{}),
+		accounts: map[string]*txAccount{},
+		classes:  map[string]AccountClass{},
+		txStore:  NewChainTxStore(),
+		status:   newStatusTracker(),
+	}
+
+	// the configured class hash and salt must deploy to the account address
+	err = txm.deployAccount(context.Background(), client, "SN_GOERLI", new(felt.Felt).SetUint64(1), publicKey)
+	require.ErrorContains(t, err, "configured account class hash and salt deploy to")
+
+	require.NoError(t, txm.deployAccount(context.Background(), client, "SN_GOERLI", address, publicKey))
+	assert.True(t, txm.txStore.Deploying(address))
+	rec, err := txm.txStore.Get("0xdead")
+	require.NoError(t, err)
+	assert.Equal(t, starknetrpc.TransactionType_DeployAccount, rec.Type)
+	assert.Equal(t, &felt.Zero, rec.Nonce)
+
+	// nothing is sent while the deploy is inflight
+	inflight := &starknet.Client{Provider: newTestProvider(t, map[string]string{"starknet_chainId": chainID})}
+	require.NoError(t, txm.deployAccount(context.Background(), inflight, "SN_GOERLI", address, publicKey))
+
+	// the account has no on-chain nonce until the deploy is included, txs use the nonce after it
+	nonceClient := mocks.NewNonceManagerClient(t)
+	nonceClient.On("AccountNonce", mock.Anything, mock.Anything).Return(nil, errors.Wrap(starknet.ErrContractNotDeployed, "error in client.AccountNonce"))
+	next, err := (&pendingNonceClient{nonceClient, txm.txStore}).AccountNonce(context.Background(), address)
+	require.NoError(t, err)
+	assert.Equal(t, new(felt.Felt).SetUint64(1), next)
+}
+
+func TestStarkTxm_DeployAccount_ConcurrentEnqueue(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestProvider(t, map[string]string{
+		"starknet_chainId":                     `{"jsonrpc": "2.0", "id": 1, "result": "0x534e5f474f45524c49"}`,
+		"starknet_getNonce":                    `{"jsonrpc": "2.0", "id": 1, "error": {"code": 20, "message": "Contract not found"}}`,
+		"starknet_getClassHashAt":              `{"jsonrpc": "2.0", "id": 1, "error": {"code": 20, "message": "Contract not found"}}`,
+		"starknet_getClass":                    sierraClass(`[{"type": "function", "name": "__execute__"}]`),
+		"starknet_estimateFee":                 `{"jsonrpc": "2.0", "id": 1, "result": [{"gas_consumed": "0x10", "gas_price": "0x5", "overall_fee": "0x50", "unit": "FRI"}]}`,
+		"starknet_addDeployAccountTransaction": `{"jsonrpc": "2.0", "id": 1, "result": {"transaction_hash": "0xdead", "contract_address": "0x1"}}`,
+	})
+	client := &starknet.Client{Provider: provider}
+
+	publicKey := new(felt.Felt).SetUint64(0xbeef)
+	classHash := new(felt.Felt).SetUint64(0xc1a55)
+	salt := new(felt.Felt).SetUint64(7)
+	account, err := starknetaccount.NewAccount(provider, &felt.Zero, publicKey.String(), nil, 2)
+	require.NoError(t, err)
+	address, err := account.PrecomputeAddress(&felt.Zero, salt, classHash, []*felt.Felt{publicKey})
+	require.NoError(t, err)
+
+	cfg := mocks.NewConfig(t)
+	cfg.On("AccountClassHash").Return(classHash)
+	cfg.On("AccountSalt").Return(salt)
+	cfg.On("L1GasAmountPercent").Return(uint32(100))
+	cfg.On("L1GasPricePercent").Return(uint32(100))
+	cfg.On("L2GasAmountPercent").Return(uint32(0))
+	cfg.On("L2GasPricePercent").Return(uint32(0))
+	cfg.On("L1GasMaxPrice").Return(uint64(0))
+	cfg.On("L2GasMaxPrice").Return(uint64(0))
+	cfg.On("MaxFee").Return(uint64(0))
+	cfg.On("TipStrategy").Return(string(TipNone))
+	cfg.On("Tip").Return(uint64(0))
+	cfg.On("NonceDAMode").Return(starknetrpc.DAModeL1)
+	cfg.On("FeeDAMode").Return(starknetrpc.DAModeL1)
+	cfg.On("DryRun").Return(false)
+	cfg.On("TxTimeout").Return(time.Second)
+
+	txm := &starktxm{
+		lggr:     logger.Test(t),
+		cfg:      cfg,
+		ks:       NewKeystoreAdapter(fixedSigKeystore{}),
+		nonce:    NewNonceManager(logger.Test(t)),
+		accounts: map[string]*txAccount{},
+		classes:  map[string]AccountClass{},
+		client:   utils.NewLazyLoad(func() (*starknet.Client, error) { return client, nil }),
+		txStore:  NewChainTxStore(),
+		status:   newStatusTracker(),
+	}
+
+	// every enqueue finds the account undeployed, a second deploy would fail to be saved with the nonce in use
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := txm.Enqueue(address, publicKey, starknetrpc.FunctionCall{ContractAddress: address, EntryPointSelector: &felt.Zero}, TxOpts{})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.True(t, txm.txStore.Deploying(address))
+	assert.Equal(t, 1, txm.txStore.GetAllInflightCount()[address])
+	assert.Equal(t, 5, txm.account("SN_GOERLI", address, publicKey).queue.Len())
+}
@@ -0,0 +1,173 @@
+package txm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	starknetutils "github.com/NethermindEth/starknet.go/utils"
+)
+
+var ErrFeeOverBudget = errors.New("tx fee over budget")
+
+const (
+	// FeeBumpPercent is the minimum increase of each price bound and the tip when replacing a stuck tx
+	FeeBumpPercent = 20
+	// MaxTxAttempts is the maximum number of attempts sent for a single nonce
+	MaxTxAttempts = 5
+)
+
+type TipStrategy string
+
+const (
+	TipNone    TipStrategy = "none"    // no tip
+	TipFixed   TipStrategy = "fixed"   // tip is [FeePolicy.Tip]
+	TipPercent TipStrategy = "percent" // tip is [FeePolicy.Tip] percent of the estimated gas price
+)
+
+// FeePolicy controls how the resource bounds of a tx are derived from its fee estimate.
+// The estimate of this RPC version has a single gas amount and price (including data gas) that covers the L1 gas
+// of the tx, it is scaled by the percentages of one resource into its bounds. Scaling it into both the L1 and L2
+// bounds would commit to paying the estimate twice, so only one of the amount percentages should be set.
+type FeePolicy struct {
+	// L1GasAmountPercent and L1GasPricePercent scale the estimate into the L1 gas bounds, 0 leaves the bound at 0
+	L1GasAmountPercent uint32
+	L1GasPricePercent  uint32
+	// L2GasAmountPercent and L2GasPricePercent scale the estimate into the L2 gas bounds, 0 leaves the bound at 0
+	L2GasAmountPercent uint32
+	L2GasPricePercent  uint32
+	// L1GasMaxPrice and L2GasMaxPrice cap the max price per unit, txs estimated above the cap are rejected. 0 is uncapped
+	L1GasMaxPrice uint64
+	L2GasMaxPrice uint64
+	// MaxFee is the max total fee a tx can be charged (in FRI), txs with higher bounds are rejected. 0 is unlimited
+	MaxFee      uint64
+	TipStrategy TipStrategy
+	// Tip is the fixed tip, or the percent of the gas price for [TipPercent]
+	Tip         uint64
+	NonceDAMode starknetrpc.DataAvailabilityMode
+	FeeDAMode   starknetrpc.DataAvailabilityMode
+}
+
+// NewFeePolicy reads the fee policy from the config
+func NewFeePolicy(cfg Config) FeePolicy {
+	return FeePolicy{
+		L1GasAmountPercent: cfg.L1GasAmountPercent(),
+		L1GasPricePercent:  cfg.L1GasPricePercent(),
+		L2GasAmountPercent: cfg.L2GasAmountPercent(),
+		L2GasPricePercent:  cfg.L2GasPricePercent(),
+		L1GasMaxPrice:      cfg.L1GasMaxPrice(),
+		L2GasMaxPrice:      cfg.L2GasMaxPrice(),
+		MaxFee:             cfg.MaxFee(),
+		TipStrategy:        TipStrategy(cfg.TipStrategy()),
+		Tip:                cfg.Tip(),
+		NonceDAMode:        cfg.NonceDAMode(),
+		FeeDAMode:          cfg.FeeDAMode(),
+	}
+}
+
+// ResourceBounds derives the resource bounds and tip of a tx from its fee estimate, it fails with
+// [ErrFeeOverBudget] if the estimate exceeds a price cap or the max fee
+func (p FeePolicy) ResourceBounds(estimate starknetrpc.FeeEstimate) (bounds starknetrpc.ResourceBoundsMapping, tip starknetrpc.U64, err error) {
+	gas := estimate.GasConsumed.BigInt(new(big.Int))
+	price := estimate.GasPrice.BigInt(new(big.Int))
+	if p.MaxFee > 0 && estimate.OverallFee.BigInt(new(big.Int)).Cmp(new(big.Int).SetUint64(p.MaxFee)) > 0 {
+		return bounds, tip, fmt.Errorf("%w: estimated fee %s > max fee %d", ErrFeeOverBudget, estimate.OverallFee, p.MaxFee)
+	}
+
+	resource := func(name string, amountPercent, pricePercent uint32, maxPrice uint64) (starknetrpc.ResourceBounds, error) {
+		amount := percentOf(gas, uint64(amountPercent))
+		unitPrice := percentOf(price, uint64(pricePercent))
+		if maxPrice > 0 && unitPrice.Cmp(new(big.Int).SetUint64(maxPrice)) > 0 {
+			// pay up to the cap as long as the estimated price is below it
+			if pricePercent > 0 && price.Cmp(new(big.Int).SetUint64(maxPrice)) > 0 {
+				return starknetrpc.ResourceBounds{}, fmt.Errorf("%w: estimated %s gas price %s > max price %d", ErrFeeOverBudget, name, price, maxPrice)
+			}
+			unitPrice.SetUint64(maxPrice)
+		}
+		return starknetrpc.ResourceBounds{
+			MaxAmount:       starknetrpc.U64(toHex(amount)),
+			MaxPricePerUnit: starknetrpc.U128(toHex(unitPrice)),
+		}, nil
+	}
+	if bounds.L1Gas, err = resource("L1", p.L1GasAmountPercent, p.L1GasPricePercent, p.L1GasMaxPrice); err != nil {
+		return bounds, tip, err
+	}
+	if bounds.L2Gas, err = resource("L2", p.L2GasAmountPercent, p.L2GasPricePercent, p.L2GasMaxPrice); err != nil {
+		return bounds, tip, err
+	}
+
+	switch p.TipStrategy {
+	case TipFixed:
+		tip = starknetrpc.U64(toHex(new(big.Int).SetUint64(p.Tip)))
+	case TipPercent:
+		tip = starknetrpc.U64(toHex(percentOf(price, p.Tip)))
+	default:
+		tip = "0x0"
+	}
+	return bounds, tip, p.CheckBudget(bounds, tip)
+}
+
+// CheckBudget fails with [ErrFeeOverBudget] if the max fee the bounds commit to exceeds [FeePolicy.MaxFee]
+func (p FeePolicy) CheckBudget(bounds starknetrpc.ResourceBoundsMapping, tip starknetrpc.U64) error {
+	if p.MaxFee == 0 {
+		return nil
+	}
+	if fee := maxFee(bounds, tip); fee.Cmp(new(big.Int).SetUint64(p.MaxFee)) > 0 {
+		return fmt.Errorf("%w: max fee %s of resource bounds > %d", ErrFeeOverBudget, fee, p.MaxFee)
+	}
+	return nil
+}
+
+// maxFee is the highest fee that can be charged for a tx with the bounds, the tip is paid per unit of L2 gas
+func maxFee(bounds starknetrpc.ResourceBoundsMapping, tip starknetrpc.U64) *big.Int {
+	l1 := new(big.Int).Mul(parseHex(string(bounds.L1Gas.MaxAmount)), parseHex(string(bounds.L1Gas.MaxPricePerUnit)))
+	l2Price := new(big.Int).Add(parseHex(string(bounds.L2Gas.MaxPricePerUnit)), parseHex(string(tip)))
+	l2 := new(big.Int).Mul(parseHex(string(bounds.L2Gas.MaxAmount)), l2Price)
+	return l1.Add(l1, l2)
+}
+
+func percentOf(n *big.Int, percent uint64) *big.Int {
+	out := new(big.Int).Mul(n, new(big.Int).SetUint64(percent))
+	return out.Div(out, big.NewInt(100))
+}
+
+// bumpResourceBounds returns bounds that are at least the next (freshly estimated) bounds and at least
+// [FeeBumpPercent] higher than the prices of the previous attempt
+func bumpResourceBounds(next starknetrpc.ResourceBoundsMapping, nextTip starknetrpc.U64, prev starknetrpc.ResourceBoundsMapping, prevTip starknetrpc.U64) (starknetrpc.ResourceBoundsMapping, starknetrpc.U64) {
+	bump := func(next, prev starknetrpc.ResourceBounds) starknetrpc.ResourceBounds {
+		return starknetrpc.ResourceBounds{
+			MaxAmount:       starknetrpc.U64(maxHex(string(next.MaxAmount), string(prev.MaxAmount), 0)),
+			MaxPricePerUnit: starknetrpc.U128(maxHex(string(next.MaxPricePerUnit), string(prev.MaxPricePerUnit), FeeBumpPercent)),
+		}
+	}
+	return starknetrpc.ResourceBoundsMapping{
+		L1Gas: bump(next.L1Gas, prev.L1Gas),
+		L2Gas: bump(next.L2Gas, prev.L2Gas),
+	}, starknetrpc.U64(maxHex(string(nextTip), string(prevTip), FeeBumpPercent))
+}
+
+// maxHex returns the larger of next and prev increased by percent, as a hex string
+func maxHex(next, prev string, percent int64) string {
+	n := parseHex(next)
+	p := parseHex(prev)
+	p.Mul(p, big.NewInt(100+percent))
+	p.Div(p, big.NewInt(100))
+	if n.Cmp(p) >= 0 {
+		return toHex(n)
+	}
+	return toHex(p)
+}
+
+// parseHex parses a hex quantity, treating empty or invalid values as 0
+func parseHex(s string) *big.Int {
+	n := starknetutils.HexToBN(s)
+	if n == nil {
+		return new(big.Int)
+	}
+	return n
+}
+
+func toHex(n *big.Int) string {
+	return starknetutils.BigIntToFelt(n).String()
+}
@@ -0,0 +1,172 @@
+package txm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+	"github.com/smartcontractkit/chainlink-common/pkg/utils"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm/mocks"
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+func TestBumpResourceBounds(t *testing.T) {
+	t.Parallel()
+
+	prev := starknetrpc.ResourceBoundsMapping{
+		L1Gas: starknetrpc.ResourceBounds{MaxAmount: "0x0", MaxPricePerUnit: "0x0"},
+		L2Gas: starknetrpc.ResourceBounds{MaxAmount: "0x64", MaxPricePerUnit: "0x64"}, // 100, 100
+	}
+
+	// estimate below previous attempt: prices bumped, amounts kept
+	next := starknetrpc.ResourceBoundsMapping{
+		L1Gas: starknetrpc.ResourceBounds{MaxAmount: "0x0", MaxPricePerUnit: "0x0"},
+		L2Gas: starknetrpc.ResourceBounds{MaxAmount: "0x50", MaxPricePerUnit: "0x50"}, // 80, 80
+	}
+	bounds, tip := bumpResourceBounds(next, "0x0", prev, "0xa")
+	assert.Equal(t, starknetrpc.U64("0x64"), bounds.L2Gas.MaxAmount)
+	assert.Equal(t, starknetrpc.U128("0x78"), bounds.L2Gas.MaxPricePerUnit) // 120
+	assert.Equal(t, starknetrpc.U64("0xc"), tip)                            // 12
+	assert.Equal(t, starknetrpc.U128("0x0"), bounds.L1Gas.MaxPricePerUnit)
+
+	// estimate above bumped previous attempt: estimate is used
+	next.L2Gas = starknetrpc.ResourceBounds{MaxAmount: "0xc8", MaxPricePerUnit: "0xc8"} // 200, 200
+	bounds, tip = bumpResourceBounds(next, "0x0", prev, "")
+	assert.Equal(t, starknetrpc.U64("0xc8"), bounds.L2Gas.MaxAmount)
+	assert.Equal(t, starknetrpc.U128("0xc8"), bounds.L2Gas.MaxPricePerUnit)
+	assert.Equal(t, starknetrpc.U64("0x0"), tip)
+}
+
+func TestFeePolicy_ResourceBounds(t *testing.T) {
+	t.Parallel()
+
+	estimate := starknetrpc.FeeEstimate{
+		GasConsumed: new(felt.Felt).SetUint64(100),
+		GasPrice:    new(felt.Felt).SetUint64(10),
+		OverallFee:  new(felt.Felt).SetUint64(1000),
+		FeeUnit:     starknetrpc.UnitStrk,
+	}
+	defaults := FeePolicy{L2GasAmountPercent: 140, L2GasPricePercent: 100, TipStrategy: TipNone}
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+		bounds, tip, err := defaults.ResourceBounds(estimate)
+		require.NoError(t, err)
+		assert.Equal(t, starknetrpc.ResourceBounds{MaxAmount: "0x0", MaxPricePerUnit: "0x0"}, bounds.L1Gas)
+		assert.Equal(t, starknetrpc.ResourceBounds{MaxAmount: "0x8c", MaxPricePerUnit: "0xa"}, bounds.L2Gas) // 140, 10
+		assert.Equal(t, starknetrpc.U64("0x0"), tip)
+	})
+
+	t.Run("multipliers and tip", func(t *testing.T) {
+		t.Parallel()
+		p := FeePolicy{L1GasAmountPercent: 150, L1GasPricePercent: 200, TipStrategy: TipPercent, Tip: 50}
+		bounds, tip, err := p.ResourceBounds(estimate)
+		require.NoError(t, err)
+		assert.Equal(t, starknetrpc.ResourceBounds{MaxAmount: "0x96", MaxPricePerUnit: "0x14"}, bounds.L1Gas) // 150, 20
+		assert.Equal(t, starknetrpc.ResourceBounds{MaxAmount: "0x0", MaxPricePerUnit: "0x0"}, bounds.L2Gas)
+		assert.Equal(t, starknetrpc.U64("0x5"), tip)
+
+		p.TipStrategy = TipFixed
+		_, tip, err = p.ResourceBounds(estimate)
+		require.NoError(t, err)
+		assert.Equal(t, starknetrpc.U64("0x32"), tip)
+
+		// percents above 32 bits are not truncated
+		p.TipStrategy = TipPercent
+		p.Tip = 1<<32 + 50
+		_, tip, err = p.ResourceBounds(estimate)
+		require.NoError(t, err)
+		assert.Equal(t, starknetrpc.U64("0x1999999e"), tip) // 10 * (2^32 + 50) / 100
+	})
+
+	t.Run("price cap", func(t *testing.T) {
+		t.Parallel()
+		p := defaults
+		p.L2GasPricePercent = 150
+		p.L2GasMaxPrice = 12
+		bounds, _, err := p.ResourceBounds(estimate)
+		require.NoError(t, err)
+		assert.Equal(t, starknetrpc.U128("0xc"), bounds.L2Gas.MaxPricePerUnit) // capped at 12
+
+		p.L2GasMaxPrice = 5
+		_, _, err = p.ResourceBounds(estimate)
+		require.ErrorIs(t, err, ErrFeeOverBudget)
+	})
+
+	t.Run("max fee", func(t *testing.T) {
+		t.Parallel()
+		p := defaults
+		p.MaxFee = 1400
+		_, _, err := p.ResourceBounds(estimate)
+		require.NoError(t, err)
+
+		p.MaxFee = 1399
+		_, _, err = p.ResourceBounds(estimate)
+		require.ErrorIs(t, err, ErrFeeOverBudget)
+
+		p.MaxFee = 999
+		_, _, err = p.ResourceBounds(estimate)
+		require.ErrorContains(t, err, "estimated fee")
+	})
+
+	t.Run("budget just above the estimate", func(t *testing.T) {
+		t.Parallel()
+		// the estimate is only scaled into the L1 bounds, so a budget above it is enough
+		p := FeePolicy{L1GasAmountPercent: 100, L1GasPricePercent: 100, MaxFee: 1001}
+		bounds, _, err := p.ResourceBounds(estimate)
+		require.NoError(t, err)
+		assert.Equal(t, 0, maxFee(bounds, "0x0").Cmp(big.NewInt(1000)))
+
+		// the chain defaults commit to 115% of the gas at 150% of the price
+		p = FeePolicy{L1GasAmountPercent: 115, L1GasPricePercent: 150, MaxFee: 1725}
+		_, _, err = p.ResourceBounds(estimate)
+		require.NoError(t, err)
+	})
+}
+
+func TestStarkTxm_EstimateFee(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestProvider(t, map[string]string{
+		"starknet_chainId":        `{"jsonrpc": "2.0", "id": 1, "result": "0x534e5f474f45524c49"}`,
+		"starknet_getNonce":       `{"jsonrpc": "2.0", "id": 1, "result": "0x3"}`,
+		"starknet_getClassHashAt": `{"jsonrpc": "2.0", "id": 1, "result": "0xc1a55"}`,
+		"starknet_getClass":       sierraClass(`[{"type": "function", "name": "__execute__"}]`),
+		"starknet_estimateFee":    `{"jsonrpc": "2.0", "id": 1, "result": [{"gas_consumed": "0x10", "gas_price": "0x5", "overall_fee": "0x50", "unit": "FRI"}]}`,
+	})
+	client := &starknet.Client{Provider: provider}
+
+	cfg := mocks.NewConfig(t)
+	cfg.On("L1GasAmountPercent").Return(uint32(0))
+	cfg.On("L1GasPricePercent").Return(uint32(0))
+	cfg.On("L2GasAmountPercent").Return(uint32(140))
+	cfg.On("L2GasPricePercent").Return(uint32(100))
+	cfg.On("L1GasMaxPrice").Return(uint64(0))
+	cfg.On("L2GasMaxPrice").Return(uint64(0))
+	cfg.On("MaxFee").Return(uint64(0))
+	cfg.On("TipStrategy").Return(string(TipNone))
+	cfg.On("Tip").Return(uint64(0))
+	cfg.On("NonceDAMode").Return(starknetrpc.DAModeL1)
+	cfg.On("FeeDAMode").Return(starknetrpc.DAModeL1)
+
+	txm := &starktxm{
+		lggr:    logger.Test(t),
+		cfg:     cfg,
+		ks:      NewKeystoreAdapter(fixedSigKeystore{}),
+		classes: map[string]AccountClass{},
+		client:  utils.NewLazyLoad(func() (*starknet.Client, error) { return client, nil }),
+	}
+
+	call := starknetrpc.FunctionCall{ContractAddress: new(felt.Felt).SetUint64(2), EntryPointSelector: &felt.Zero}
+	fee, err := txm.EstimateFee(context.Background(), new(felt.Felt).SetUint64(1), new(felt.Felt).SetUint64(0xbeef), []starknetrpc.FunctionCall{call})
+	require.NoError(t, err)
+	// 140% of the 16 gas consumed at 5 FRI
+	assert.Equal(t, big.NewInt(22*5), fee)
+}
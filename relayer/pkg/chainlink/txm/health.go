@@ -0,0 +1,78 @@
+package txm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+var (
+	// ErrQueueSaturated is reported when an account queue is close to [MaxQueueLen]
+	ErrQueueSaturated = errors.New("tx queue saturated")
+	// ErrUnconfirmedTooOld is reported when a broadcast tx stays unconfirmed for longer than the max unconfirmed age
+	ErrUnconfirmedTooOld = errors.New("unconfirmed tx too old")
+	// ErrNonceErrors is reported when broadcasts from an account keep failing with nonce errors
+	ErrNonceErrors = errors.New("repeated nonce errors")
+)
+
+// snapshotAccounts returns the accounts that enqueued txs, sorted by address
+func (txm *starktxm) snapshotAccounts() []*txAccount {
+	txm.accountsLock.RLock()
+	accounts := maps.Values(txm.accounts)
+	txm.accountsLock.RUnlock()
+	slices.SortFunc(accounts, func(a, b *txAccount) int { return a.address.Cmp(b.address) })
+	return accounts
+}
+
+// queueHealth reports the accounts whose queue is filled above the saturation percent of [MaxQueueLen]
+func (txm *starktxm) queueHealth() error {
+	percent := txm.cfg.QueueSaturationPercent()
+	if percent == 0 {
+		return nil
+	}
+	limit := MaxQueueLen * int(percent) / 100
+	var errs []error
+	for _, acc := range txm.snapshotAccounts() {
+		if n := acc.queue.Len(); n >= limit {
+			errs = append(errs, fmt.Errorf("%w: %s has %d/%d txs queued", ErrQueueSaturated, acc.address, n, MaxQueueLen))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// unconfirmedAgeHealth reports if the oldest unconfirmed tx was broadcast longer than the max unconfirmed age ago
+func (txm *starktxm) unconfirmedAgeHealth() error {
+	maxAge := txm.cfg.MaxUnconfirmedAge()
+	if maxAge == 0 || txm.txStore == nil {
+		return nil
+	}
+	rec, found, err := txm.txStore.OldestUnconfirmed()
+	if err != nil {
+		return fmt.Errorf("failed to read unconfirmed txs: %w", err)
+	}
+	if !found {
+		return nil
+	}
+	if age := time.Since(rec.CreatedAt); age > maxAge {
+		return fmt.Errorf("%w: %s from %s unconfirmed for %s > %s", ErrUnconfirmedTooOld, rec.Hash, rec.AccountAddress, age.Round(time.Second), maxAge)
+	}
+	return nil
+}
+
+// nonceHealth reports the accounts whose last broadcasts failed with at least the max number of nonce errors in a row
+func (txm *starktxm) nonceHealth() error {
+	maxErrors := txm.cfg.MaxNonceErrors()
+	if maxErrors == 0 {
+		return nil
+	}
+	var errs []error
+	for _, acc := range txm.snapshotAccounts() {
+		if n := acc.nonceErrors.Load(); n >= maxErrors {
+			errs = append(errs, fmt.Errorf("%w: %s failed %d broadcasts in a row", ErrNonceErrors, acc.address, n))
+		}
+	}
+	return errors.Join(errs...)
+}
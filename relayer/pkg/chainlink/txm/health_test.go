@@ -0,0 +1,69 @@
+package txm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm/mocks"
+)
+
+func TestStarkTxm_HealthChecks(t *testing.T) {
+	t.Parallel()
+
+	address := new(felt.Felt).SetUint64(0xbeef)
+	acc := newTxAccount("SN_HEALTH", address, &felt.Zero)
+	cfg := mocks.NewConfig(t)
+	txm := &starktxm{
+		lggr:     logger.Test(t),
+		cfg:      cfg,
+		txStore:  NewChainTxStore(),
+		accounts: map[string]*txAccount{address.String(): acc},
+	}
+
+	t.Run("queue saturation", func(t *testing.T) {
+		cfg.On("QueueSaturationPercent").Return(uint32(1)).Once()
+		assert.NoError(t, txm.queueHealth())
+
+		for i := 0; i < MaxQueueLen/100; i++ {
+			_, err := acc.queue.Push(Tx{id: "queued", call: starknetrpc.FunctionCall{ContractAddress: address}})
+			require.NoError(t, err)
+		}
+		cfg.On("QueueSaturationPercent").Return(uint32(1)).Once()
+		assert.ErrorIs(t, txm.queueHealth(), ErrQueueSaturated)
+
+		// disabled
+		cfg.On("QueueSaturationPercent").Return(uint32(0)).Once()
+		assert.NoError(t, txm.queueHealth())
+	})
+
+	t.Run("unconfirmed age", func(t *testing.T) {
+		cfg.On("MaxUnconfirmedAge").Return(time.Millisecond)
+		assert.NoError(t, txm.unconfirmedAgeHealth())
+
+		require.NoError(t, txm.txStore.Save(TxRecord{Hash: "0x1", AccountAddress: address, Nonce: new(felt.Felt).SetUint64(3)}))
+		require.Eventually(t, func() bool {
+			return txm.unconfirmedAgeHealth() != nil
+		}, time.Second, 5*time.Millisecond)
+		assert.ErrorIs(t, txm.unconfirmedAgeHealth(), ErrUnconfirmedTooOld)
+
+		require.NoError(t, txm.txStore.Confirm(address, "0x1", TxOutcome{}))
+		assert.NoError(t, txm.unconfirmedAgeHealth())
+	})
+
+	t.Run("nonce errors", func(t *testing.T) {
+		cfg.On("MaxNonceErrors").Return(uint32(2))
+		acc.nonceErrors.Add(1)
+		assert.NoError(t, txm.nonceHealth())
+		acc.nonceErrors.Add(1)
+		assert.ErrorIs(t, txm.nonceHealth(), ErrNonceErrors)
+		acc.nonceErrors.Store(0)
+		assert.NoError(t, txm.nonceHealth())
+	})
+}
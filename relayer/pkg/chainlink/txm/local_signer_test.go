@@ -0,0 +1,87 @@
+package txm
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sort"
+
+	"github.com/NethermindEth/starknet.go/curve"
+)
+
+var _ http.Handler = (*LocalSigner)(nil)
+
+// LocalSigner serves the remote signer API used by the [RemoteKeystore] with keys held in memory, as a mock signer for tests
+type LocalSigner struct {
+	keys map[string]*big.Int
+	mux  *http.ServeMux
+}
+
+// NewLocalSigner returns a signer for the private keys by public key
+func NewLocalSigner(keys map[string]*big.Int) *LocalSigner {
+	s := &LocalSigner{keys: keys, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/sign", s.sign)
+	s.mux.HandleFunc("/accounts", s.accounts)
+	return s
+}
+
+func (s *LocalSigner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *LocalSigner) sign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeSignerError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req signRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSignerError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	key, exists := s.keys[req.ID]
+	if !exists {
+		writeSignerError(w, http.StatusNotFound, "key not found")
+		return
+	}
+	if req.Hash == "" {
+		writeSignerJSON(w, signResponse{})
+		return
+	}
+
+	hash, ok := new(big.Int).SetString(req.Hash, 0)
+	if !ok {
+		writeSignerError(w, http.StatusBadRequest, "invalid hash")
+		return
+	}
+	x, y, err := curve.Curve.Sign(hash, key)
+	if err != nil {
+		writeSignerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeSignerJSON(w, signResponse{R: "0x" + x.Text(16), S: "0x" + y.Text(16)})
+}
+
+func (s *LocalSigner) accounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeSignerError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	accounts := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		accounts = append(accounts, id)
+	}
+	sort.Strings(accounts)
+	writeSignerJSON(w, accountsResponse{Accounts: accounts})
+}
+
+func writeSignerJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeSignerError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(signerError{Error: msg})
+}
@@ -0,0 +1,68 @@
+package txm
+
+import (
+	"math/big"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	promTxConfirmed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "starknet_txm_confirmed_txs",
+		Help: "Number of txs included or rejected on chain, by status (CONFIRMED, REVERTED or REJECTED)",
+	}, []string{"chain_id", "account", "status"})
+	promQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "starknet_txm_queue_depth",
+		Help: "Number of txs queued for an account that were not broadcast yet",
+	}, []string{"chain_id", "account"})
+	promBroadcastDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "starknet_txm_broadcast_duration_seconds",
+		Help:    "Time to build, sign, estimate and submit a tx",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+	}, []string{"chain_id", "account"})
+	promInclusionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "starknet_txm_inclusion_duration_seconds",
+		Help:    "Time from the broadcast of a tx until it was included on chain",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"chain_id", "account"})
+	promFeesPaid = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "starknet_txm_fees_paid_fri",
+		Help: "Sum of the actual fees charged for included txs in FRI, by called contract",
+	}, []string{"chain_id", "account", "contract"})
+	promNonceResyncs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "starknet_txm_nonce_resyncs",
+		Help: "Number of times the local nonce of an account was overwritten with a different on-chain nonce",
+	}, []string{"chain_id", "account"})
+	promEstimateFeeFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "starknet_txm_estimate_fee_failures",
+		Help: "Number of failed fee estimates, e.g. because the tx would revert",
+	}, []string{"chain_id", "account"})
+	promAccountBalance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "starknet_txm_account_balance",
+		Help: "Fee token balance of an account that enqueued txs, in the smallest unit of the token (FRI for STRK, wei for ETH)",
+	}, []string{"chain_id", "account", "token"})
+)
+
+// multicallContract is the contract label of fees paid for multicalls to different contracts
+const multicallContract = "multicall"
+
+// calledContract returns the contract called by every call of the tx, or [multicallContract]
+func calledContract(calls []starknetrpc.FunctionCall) string {
+	if len(calls) == 0 {
+		return ""
+	}
+	for _, call := range calls[1:] {
+		if !call.ContractAddress.Equal(calls[0].ContractAddress) {
+			return multicallContract
+		}
+	}
+	return calls[0].ContractAddress.String()
+}
+
+func feltToFloat(f *felt.Felt) float64 {
+	v, _ := new(big.Float).SetInt(f.BigInt(new(big.Int))).Float64()
+	return v
+}
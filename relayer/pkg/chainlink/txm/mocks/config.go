@@ -3,8 +3,11 @@
 package mocks
 
 import (
+	big "math/big"
 	time "time"
 
+	felt "github.com/NethermindEth/juno/core/felt"
+	rpc "github.com/NethermindEth/starknet.go/rpc"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -13,6 +16,80 @@ type Config struct {
 	mock.Mock
 }
 
+// AccountClassHash provides a mock function with given fields:
+func (_m *Config) AccountClassHash() *felt.Felt {
+	ret := _m.Called()
+
+	var r0 *felt.Felt
+	if rf, ok := ret.Get(0).(func() *felt.Felt); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*felt.Felt)
+		}
+	}
+
+	return r0
+}
+
+// AccountSalt provides a mock function with given fields:
+func (_m *Config) AccountSalt() *felt.Felt {
+	ret := _m.Called()
+
+	var r0 *felt.Felt
+	if rf, ok := ret.Get(0).(func() *felt.Felt); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*felt.Felt)
+		}
+	}
+
+	return r0
+}
+
+// BalanceGating provides a mock function with given fields:
+func (_m *Config) BalanceGating() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// BalancePollInterval provides a mock function with given fields:
+func (_m *Config) BalancePollInterval() time.Duration {
+	ret := _m.Called()
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// BatchWindow provides a mock function with given fields:
+func (_m *Config) BatchWindow() time.Duration {
+	ret := _m.Called()
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
 // ConfirmationPoll provides a mock function with given fields:
 func (_m *Config) ConfirmationPoll() time.Duration {
 	ret := _m.Called()
@@ -27,6 +104,392 @@ func (_m *Config) ConfirmationPoll() time.Duration {
 	return r0
 }
 
+// DryRun provides a mock function with given fields:
+func (_m *Config) DryRun() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// FeeDAMode provides a mock function with given fields:
+func (_m *Config) FeeDAMode() rpc.DataAvailabilityMode {
+	ret := _m.Called()
+
+	var r0 rpc.DataAvailabilityMode
+	if rf, ok := ret.Get(0).(func() rpc.DataAvailabilityMode); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(rpc.DataAvailabilityMode)
+	}
+
+	return r0
+}
+
+// FeeTokenAddress provides a mock function with given fields:
+func (_m *Config) FeeTokenAddress() *felt.Felt {
+	ret := _m.Called()
+
+	var r0 *felt.Felt
+	if rf, ok := ret.Get(0).(func() *felt.Felt); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*felt.Felt)
+		}
+	}
+
+	return r0
+}
+
+// FinalityTarget provides a mock function with given fields:
+func (_m *Config) FinalityTarget() rpc.TxnStatus {
+	ret := _m.Called()
+
+	var r0 rpc.TxnStatus
+	if rf, ok := ret.Get(0).(func() rpc.TxnStatus); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(rpc.TxnStatus)
+	}
+
+	return r0
+}
+
+// L1GasAmountPercent provides a mock function with given fields:
+func (_m *Config) L1GasAmountPercent() uint32 {
+	ret := _m.Called()
+
+	var r0 uint32
+	if rf, ok := ret.Get(0).(func() uint32); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint32)
+	}
+
+	return r0
+}
+
+// L1GasMaxPrice provides a mock function with given fields:
+func (_m *Config) L1GasMaxPrice() uint64 {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	return r0
+}
+
+// L1GasPricePercent provides a mock function with given fields:
+func (_m *Config) L1GasPricePercent() uint32 {
+	ret := _m.Called()
+
+	var r0 uint32
+	if rf, ok := ret.Get(0).(func() uint32); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint32)
+	}
+
+	return r0
+}
+
+// L2GasAmountPercent provides a mock function with given fields:
+func (_m *Config) L2GasAmountPercent() uint32 {
+	ret := _m.Called()
+
+	var r0 uint32
+	if rf, ok := ret.Get(0).(func() uint32); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint32)
+	}
+
+	return r0
+}
+
+// L2GasMaxPrice provides a mock function with given fields:
+func (_m *Config) L2GasMaxPrice() uint64 {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	return r0
+}
+
+// L2GasPricePercent provides a mock function with given fields:
+func (_m *Config) L2GasPricePercent() uint32 {
+	ret := _m.Called()
+
+	var r0 uint32
+	if rf, ok := ret.Get(0).(func() uint32); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint32)
+	}
+
+	return r0
+}
+
+// MaxBatchGas provides a mock function with given fields:
+func (_m *Config) MaxBatchGas() uint64 {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	return r0
+}
+
+// MaxBatchSize provides a mock function with given fields:
+func (_m *Config) MaxBatchSize() int {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// MaxFee provides a mock function with given fields:
+func (_m *Config) MaxFee() uint64 {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	return r0
+}
+
+// MaxNonceErrors provides a mock function with given fields:
+func (_m *Config) MaxNonceErrors() uint32 {
+	ret := _m.Called()
+
+	var r0 uint32
+	if rf, ok := ret.Get(0).(func() uint32); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint32)
+	}
+
+	return r0
+}
+
+// MaxUnconfirmedAge provides a mock function with given fields:
+func (_m *Config) MaxUnconfirmedAge() time.Duration {
+	ret := _m.Called()
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// MinBalance provides a mock function with given fields:
+func (_m *Config) MinBalance() *big.Int {
+	ret := _m.Called()
+
+	var r0 *big.Int
+	if rf, ok := ret.Get(0).(func() *big.Int); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*big.Int)
+		}
+	}
+
+	return r0
+}
+
+// NonceDAMode provides a mock function with given fields:
+func (_m *Config) NonceDAMode() rpc.DataAvailabilityMode {
+	ret := _m.Called()
+
+	var r0 rpc.DataAvailabilityMode
+	if rf, ok := ret.Get(0).(func() rpc.DataAvailabilityMode); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(rpc.DataAvailabilityMode)
+	}
+
+	return r0
+}
+
+// NonceSyncInterval provides a mock function with given fields:
+func (_m *Config) NonceSyncInterval() time.Duration {
+	ret := _m.Called()
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// PaymasterAddress provides a mock function with given fields:
+func (_m *Config) PaymasterAddress() *felt.Felt {
+	ret := _m.Called()
+
+	var r0 *felt.Felt
+	if rf, ok := ret.Get(0).(func() *felt.Felt); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*felt.Felt)
+		}
+	}
+
+	return r0
+}
+
+// PaymasterData provides a mock function with given fields:
+func (_m *Config) PaymasterData() []*felt.Felt {
+	ret := _m.Called()
+
+	var r0 []*felt.Felt
+	if rf, ok := ret.Get(0).(func() []*felt.Felt); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*felt.Felt)
+		}
+	}
+
+	return r0
+}
+
+// QueueSaturationPercent provides a mock function with given fields:
+func (_m *Config) QueueSaturationPercent() uint32 {
+	ret := _m.Called()
+
+	var r0 uint32
+	if rf, ok := ret.Get(0).(func() uint32); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint32)
+	}
+
+	return r0
+}
+
+// SimulateTxs provides a mock function with given fields:
+func (_m *Config) SimulateTxs() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// StuckTxTimeout provides a mock function with given fields:
+func (_m *Config) StuckTxTimeout() time.Duration {
+	ret := _m.Called()
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// Tip provides a mock function with given fields:
+func (_m *Config) Tip() uint64 {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	return r0
+}
+
+// TipStrategy provides a mock function with given fields:
+func (_m *Config) TipStrategy() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// TxStorePath provides a mock function with given fields:
+func (_m *Config) TxStorePath() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// TxStoreRetention provides a mock function with given fields:
+func (_m *Config) TxStoreRetention() time.Duration {
+	ret := _m.Called()
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
 // TxTimeout provides a mock function with given fields:
 func (_m *Config) TxTimeout() time.Duration {
 	ret := _m.Called()
@@ -25,6 +25,8 @@ type NonceManager interface {
 
 	NextSequence(address *felt.Felt, chainID string) (*felt.Felt, error)
 	IncrementNextSequence(address *felt.Felt, chainID string, currentNonce *felt.Felt) error
+	// Sync re-fetches the nonce of a registered key from the client it was registered with and overwrites the local nonce
+	Sync(ctx context.Context, publicKey *felt.Felt, chainID string) (prev *felt.Felt, next *felt.Felt, err error)
 }
 
 var _ NonceManager = (*nonceManager)(nil)
@@ -33,14 +35,21 @@ type nonceManager struct {
 	starter utils.StartStopOnce
 	lggr    logger.Logger
 
-	n    map[string]map[string]*felt.Felt // map address + chain ID to nonce
+	n    map[string]map[string]*felt.Felt   // map address + chain ID to nonce
+	reg  map[string]map[string]registration // map address + chain ID to the account used to fetch the nonce
 	lock sync.RWMutex
 }
 
+type registration struct {
+	address *felt.Felt
+	client  NonceManagerClient
+}
+
 func NewNonceManager(lggr logger.Logger) *nonceManager {
 	return &nonceManager{
 		lggr: logger.Named(lggr, "NonceManager"),
 		n:    map[string]map[string]*felt.Felt{},
+		reg:  map[string]map[string]registration{},
 	}
 }
 
@@ -71,6 +80,7 @@ func (nm *nonceManager) Register(ctx context.Context, addr *felt.Felt, publicKey
 	addressNonces, exists := nm.n[publicKey.String()]
 	if !exists {
 		nm.n[publicKey.String()] = map[string]*felt.Felt{}
+		nm.reg[publicKey.String()] = map[string]registration{}
 	}
 	_, exists = addressNonces[chainId]
 	if !exists {
@@ -79,11 +89,38 @@ func (nm *nonceManager) Register(ctx context.Context, addr *felt.Felt, publicKey
 			return err
 		}
 		nm.n[publicKey.String()][chainId] = n
+		nm.reg[publicKey.String()][chainId] = registration{address: addr, client: client}
 	}
 
 	return nil
 }
 
+func (nm *nonceManager) Sync(ctx context.Context, publicKey *felt.Felt, chainId string) (prev *felt.Felt, next *felt.Felt, err error) {
+	if err = nm.validate(publicKey, chainId); err != nil {
+		return nil, nil, err
+	}
+
+	nm.lock.RLock()
+	r := nm.reg[publicKey.String()][chainId]
+	nm.lock.RUnlock()
+
+	// fetch without holding the lock, the caller is responsible for not using the nonce while syncing
+	next, err = r.client.AccountNonce(ctx, r.address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch nonce for %s: %w", r.address, err)
+	}
+
+	nm.lock.Lock()
+	defer nm.lock.Unlock()
+	prev = nm.n[publicKey.String()][chainId]
+	nm.n[publicKey.String()][chainId] = next
+	if prev.Cmp(next) != 0 {
+		nm.lggr.Warnw("nonce resynced", "address", r.address, "chainID", chainId, "prev", prev, "next", next)
+		promNonceResyncs.WithLabelValues(chainId, r.address.String()).Inc()
+	}
+	return prev, next, nil
+}
+
 func (nm *nonceManager) NextSequence(addr *felt.Felt, chainId string) (*felt.Felt, error) {
 	if err := nm.validate(addr, chainId); err != nil {
 		return nil, err
@@ -1,6 +1,7 @@
 package txm_test
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"testing"
@@ -20,6 +21,11 @@ import (
 )
 
 func newTestNonceManager(t *testing.T, chainID string, initNonce *felt.Felt) (txm.NonceManager, *felt.Felt, func()) {
+	nm, k, _, stop := newTestNonceManagerWithClient(t, chainID, initNonce)
+	return nm, k, stop
+}
+
+func newTestNonceManagerWithClient(t *testing.T, chainID string, initNonce *felt.Felt) (txm.NonceManager, *felt.Felt, *mocks.NonceManagerClient, func()) {
 	// setup
 	c := mocks.NewNonceManagerClient(t)
 	lggr := logger.Test(t)
@@ -33,7 +39,7 @@ func newTestNonceManager(t *testing.T, chainID string, initNonce *felt.Felt) (tx
 	require.NoError(t, nm.Start(tests.Context(t)))
 	require.NoError(t, nm.Register(tests.Context(t), keyHash, keyHash, chainID, c))
 
-	return nm, keyHash, func() { require.NoError(t, nm.Close()) }
+	return nm, keyHash, c, func() { require.NoError(t, nm.Close()) }
 }
 
 func TestNonceManager_NextSequence(t *testing.T) {
@@ -101,3 +107,37 @@ func TestNonceManager_IncrementNextSequence(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, initPlusOne, next)
 }
+
+func TestNonceManager_Sync(t *testing.T) {
+	t.Parallel()
+
+	chainId := "test_sync"
+	initNonce := new(felt.Felt).SetUint64(10)
+	nm, k, c, stop := newTestNonceManagerWithClient(t, chainId, initNonce)
+	defer stop()
+
+	require.NoError(t, nm.IncrementNextSequence(k, chainId, initNonce))
+
+	// chain nonce moved ahead (e.g. tx sent from outside the node)
+	chainNonce := new(felt.Felt).SetUint64(15)
+	c.On("AccountNonce", mock.Anything, mock.Anything).Return(chainNonce, nil).Once()
+	prev, next, err := nm.Sync(tests.Context(t), k, chainId)
+	require.NoError(t, err)
+	assert.Equal(t, new(felt.Felt).SetUint64(11), prev)
+	assert.Equal(t, chainNonce, next)
+	n, err := nm.NextSequence(k, chainId)
+	require.NoError(t, err)
+	assert.Equal(t, chainNonce, n)
+
+	// fetch failure leaves the nonce untouched
+	c.On("AccountNonce", mock.Anything, mock.Anything).Return(nil, errors.New("rpc down")).Once()
+	_, _, err = nm.Sync(tests.Context(t), k, chainId)
+	require.ErrorContains(t, err, "rpc down")
+	n, err = nm.NextSequence(k, chainId)
+	require.NoError(t, err)
+	assert.Equal(t, chainNonce, n)
+
+	// unregistered
+	_, _, err = nm.Sync(tests.Context(t), k, "invalid_chainId")
+	require.Error(t, err)
+}
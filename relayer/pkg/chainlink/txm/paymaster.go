@@ -0,0 +1,40 @@
+package txm
+
+import (
+	"context"
+	"slices"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+)
+
+//go:generate mockery --name Paymaster --output ./mocks/ --case=underscore --filename paymaster.go
+
+// Paymaster sponsors the fees of txs sent by the TXM, so sender accounts don't need to hold STRK.
+// Implementations must be safe for concurrent use.
+type Paymaster interface {
+	// PaymasterData returns the paymaster data of a tx from the sender, and the data availability mode of the fee.
+	// An empty mode keeps the configured FeeDAMode. Calls are empty for account deploys.
+	PaymasterData(ctx context.Context, sender *felt.Felt, calls []starknetrpc.FunctionCall) ([]*felt.Felt, starknetrpc.DataAvailabilityMode, error)
+}
+
+var _ Paymaster = (*configPaymaster)(nil)
+
+// configPaymaster sponsors every tx with the paymaster set in the config. Per SNIP-8 the paymaster data starts with
+// the paymaster address, followed by the data the paymaster expects.
+type configPaymaster struct {
+	data []*felt.Felt
+}
+
+// NewConfigPaymaster returns the paymaster set in the config, nil if none is configured
+func NewConfigPaymaster(cfg Config) Paymaster {
+	address := cfg.PaymasterAddress()
+	if address == nil {
+		return nil
+	}
+	return &configPaymaster{data: append([]*felt.Felt{address}, cfg.PaymasterData()...)}
+}
+
+func (p *configPaymaster) PaymasterData(context.Context, *felt.Felt, []starknetrpc.FunctionCall) ([]*felt.Felt, starknetrpc.DataAvailabilityMode, error) {
+	return slices.Clone(p.data), "", nil
+}
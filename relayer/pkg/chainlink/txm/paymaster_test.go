@@ -0,0 +1,89 @@
+package txm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm/mocks"
+)
+
+func TestConfigPaymaster(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		cfg := mocks.NewConfig(t)
+		cfg.On("PaymasterAddress").Return(nil)
+		assert.Nil(t, NewConfigPaymaster(cfg))
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		address, extra := new(felt.Felt).SetUint64(0xabc), new(felt.Felt).SetUint64(7)
+		cfg := mocks.NewConfig(t)
+		cfg.On("PaymasterAddress").Return(address)
+		cfg.On("PaymasterData").Return([]*felt.Felt{extra})
+
+		paymaster := NewConfigPaymaster(cfg)
+		require.NotNil(t, paymaster)
+		data, mode, err := paymaster.PaymasterData(context.Background(), &felt.Zero, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []*felt.Felt{address, extra}, data)
+		assert.Empty(t, mode)
+
+		// callers can't modify the configured data
+		data[0] = &felt.Zero
+		data, _, err = paymaster.PaymasterData(context.Background(), &felt.Zero, nil)
+		require.NoError(t, err)
+		assert.Equal(t, address, data[0])
+	})
+}
+
+func TestStarkTxm_Sponsor(t *testing.T) {
+	ctx := context.Background()
+	sender := new(felt.Felt).SetUint64(1)
+	calls := []starknetrpc.FunctionCall{{ContractAddress: new(felt.Felt).SetUint64(2), EntryPointSelector: &felt.Zero}}
+
+	t.Run("no paymaster", func(t *testing.T) {
+		txm := &starktxm{}
+		data, mode, err := txm.sponsor(ctx, sender, calls, starknetrpc.DAModeL1)
+		require.NoError(t, err)
+		assert.Equal(t, []*felt.Felt{}, data)
+		assert.Equal(t, starknetrpc.DAModeL1, mode)
+	})
+
+	t.Run("overrides fee mode", func(t *testing.T) {
+		paymaster := mocks.NewPaymaster(t)
+		paymaster.On("PaymasterData", mock.Anything, sender, calls).Return([]*felt.Felt{sender}, starknetrpc.DAModeL2, nil).Once()
+		txm := &starktxm{paymaster: paymaster}
+
+		data, mode, err := txm.sponsor(ctx, sender, calls, starknetrpc.DAModeL1)
+		require.NoError(t, err)
+		assert.Equal(t, []*felt.Felt{sender}, data)
+		assert.Equal(t, starknetrpc.DAModeL2, mode)
+	})
+
+	t.Run("keeps fee mode", func(t *testing.T) {
+		paymaster := mocks.NewPaymaster(t)
+		paymaster.On("PaymasterData", mock.Anything, sender, calls).Return(nil, starknetrpc.DataAvailabilityMode(""), nil).Once()
+		txm := &starktxm{paymaster: paymaster}
+
+		data, mode, err := txm.sponsor(ctx, sender, calls, starknetrpc.DAModeL1)
+		require.NoError(t, err)
+		assert.Equal(t, []*felt.Felt{}, data)
+		assert.Equal(t, starknetrpc.DAModeL1, mode)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		paymasterErr := errors.New("paymaster unavailable")
+		paymaster := mocks.NewPaymaster(t)
+		paymaster.On("PaymasterData", mock.Anything, sender, calls).Return(nil, starknetrpc.DataAvailabilityMode(""), paymasterErr).Once()
+		txm := &starktxm{paymaster: paymaster}
+
+		_, _, err := txm.sponsor(ctx, sender, calls, starknetrpc.DAModeL1)
+		assert.ErrorIs(t, err, paymasterErr)
+	})
+}
@@ -0,0 +1,136 @@
+package txm
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// txAccount is a sender account that has enqueued txs, each account is served by its own broadcast worker
+type txAccount struct {
+	chainID   string
+	address   *felt.Felt
+	publicKey *felt.Felt
+	queue     *txQueue
+
+	// sendLock is held while a nonce is read, used and incremented so that resyncs cannot interleave
+	sendLock sync.Mutex
+	// nonceErrors counts consecutive broadcasts that failed with a nonce error, reset by a successful broadcast
+	nonceErrors atomic.Uint32
+}
+
+func newTxAccount(chainID string, address, publicKey *felt.Felt) *txAccount {
+	return &txAccount{
+		chainID:   chainID,
+		address:   address,
+		publicKey: publicKey,
+		queue:     newTxQueue(),
+	}
+}
+
+// observeQueueDepth updates the queue depth metric of the account
+func (a *txAccount) observeQueueDepth() {
+	promQueueDepth.WithLabelValues(a.chainID, a.address.String()).Set(float64(a.queue.Len()))
+}
+
+// txQueue is a FIFO queue of txs for a single account. Consumers wait on [txQueue.Signal] instead of polling.
+type txQueue struct {
+	lock   sync.Mutex
+	txs    []Tx
+	signal chan struct{}
+}
+
+func newTxQueue() *txQueue {
+	return &txQueue{
+		signal: make(chan struct{}, 1),
+	}
+}
+
+// Push appends the tx and wakes up the consumer, it fails if [MaxQueueLen] txs are already queued.
+// Queued txs with the same dedup key are removed from the queue and returned as superseded.
+func (q *txQueue) Push(tx Tx) (superseded []Tx, err error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if key := tx.opts.DedupKey; key != "" {
+		q.txs = slices.DeleteFunc(q.txs, func(queued Tx) bool {
+			if queued.opts.DedupKey == key {
+				superseded = append(superseded, queued)
+				return true
+			}
+			return false
+		})
+	}
+	if len(q.txs) >= MaxQueueLen {
+		return superseded, fmt.Errorf("queue is full (%d txs)", len(q.txs))
+	}
+	q.txs = append(q.txs, tx)
+
+	// non-blocking: a pending signal already covers this tx
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+	return superseded, nil
+}
+
+// PushFront returns txs that were popped to the front of the queue, ahead of txs queued since.
+// It is not limited by [MaxQueueLen] as the txs were already accepted. Txs with the dedup key of a
+// tx queued since are not requeued and returned as superseded.
+func (q *txQueue) PushFront(txs ...Tx) (superseded []Tx) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	requeued := make([]Tx, 0, len(txs))
+	for _, tx := range txs {
+		if key := tx.opts.DedupKey; key != "" && slices.ContainsFunc(q.txs, func(queued Tx) bool { return queued.opts.DedupKey == key }) {
+			superseded = append(superseded, tx)
+			continue
+		}
+		requeued = append(requeued, tx)
+	}
+	q.txs = append(requeued, q.txs...)
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+	return superseded
+}
+
+// Pop removes the oldest tx, false if the queue is empty
+func (q *txQueue) Pop() (Tx, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if len(q.txs) == 0 {
+		return Tx{}, false
+	}
+	tx := q.txs[0]
+	q.txs[0] = Tx{} // release references held by the backing array
+	q.txs = q.txs[1:]
+	return tx, true
+}
+
+// PopN removes up to n of the oldest txs
+func (q *txQueue) PopN(n int) []Tx {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	n = min(n, len(q.txs))
+	out := make([]Tx, n)
+	copy(out, q.txs[:n])
+	clear(q.txs[:n]) // release references held by the backing array
+	q.txs = q.txs[n:]
+	return out
+}
+
+// Signal receives a value after txs are pushed, consumers should drain the queue with [txQueue.Pop] when woken up
+func (q *txQueue) Signal() <-chan struct{} {
+	return q.signal
+}
+
+func (q *txQueue) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.txs)
+}
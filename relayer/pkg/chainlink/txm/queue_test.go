@@ -0,0 +1,186 @@
+package txm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm/mocks"
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+func TestTxQueue(t *testing.T) {
+	t.Parallel()
+
+	q := newTxQueue()
+	_, ok := q.Pop()
+	assert.False(t, ok)
+
+	// no signal until a tx is pushed
+	select {
+	case <-q.Signal():
+		t.Fatal("unexpected signal on empty queue")
+	default:
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := q.Push(Tx{accountAddress: new(felt.Felt).SetUint64(uint64(i))})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, q.Len())
+
+	// multiple pushes coalesce into a single signal
+	<-q.Signal()
+	select {
+	case <-q.Signal():
+		t.Fatal("expected a single pending signal")
+	default:
+	}
+
+	// FIFO
+	for i := 0; i < 3; i++ {
+		tx, ok := q.Pop()
+		require.True(t, ok)
+		assert.Equal(t, new(felt.Felt).SetUint64(uint64(i)), tx.accountAddress)
+	}
+	assert.Equal(t, 0, q.Len())
+
+	// pop many
+	for i := 0; i < 3; i++ {
+		_, err := q.Push(Tx{accountAddress: new(felt.Felt).SetUint64(uint64(i))})
+		require.NoError(t, err)
+	}
+	txs := q.PopN(2)
+	require.Equal(t, 2, len(txs))
+	assert.Equal(t, new(felt.Felt).SetUint64(1), txs[1].accountAddress)
+	assert.Equal(t, 1, len(q.PopN(5)))
+	assert.Equal(t, 0, len(q.PopN(5)))
+
+	// full queue
+	for i := 0; i < MaxQueueLen; i++ {
+		_, err := q.Push(Tx{})
+		require.NoError(t, err)
+	}
+	_, err := q.Push(Tx{})
+	require.ErrorContains(t, err, "queue is full")
+
+	// requeued txs skip the limit and are popped first
+	q.PushFront(Tx{id: "a"}, Tx{id: "b"})
+	assert.Equal(t, MaxQueueLen+2, q.Len())
+	txs = q.PopN(3)
+	assert.Equal(t, "a", txs[0].id)
+	assert.Equal(t, "b", txs[1].id)
+	assert.Equal(t, "", txs[2].id)
+}
+
+func TestTxQueue_Dedup(t *testing.T) {
+	t.Parallel()
+
+	q := newTxQueue()
+	for _, tx := range []Tx{{id: "a", opts: TxOpts{DedupKey: "key"}}, {id: "b"}, {id: "c", opts: TxOpts{DedupKey: "other"}}} {
+		superseded, err := q.Push(tx)
+		require.NoError(t, err)
+		assert.Empty(t, superseded)
+	}
+
+	// a newer tx with the same key replaces the queued one
+	superseded, err := q.Push(Tx{id: "d", opts: TxOpts{DedupKey: "key"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(superseded))
+	assert.Equal(t, "a", superseded[0].id)
+	assert.Equal(t, 3, q.Len())
+
+	// requeued txs are dropped if a tx with the same key was queued since
+	superseded = q.PushFront(Tx{id: "a", opts: TxOpts{DedupKey: "key"}}, Tx{id: "e", opts: TxOpts{DedupKey: "new"}})
+	require.Equal(t, 1, len(superseded))
+	assert.Equal(t, "a", superseded[0].id)
+
+	var ids []string
+	for _, tx := range q.PopN(5) {
+		ids = append(ids, tx.id)
+	}
+	assert.Equal(t, []string{"e", "b", "c", "d"}, ids)
+}
+
+func TestDropExpired(t *testing.T) {
+	t.Parallel()
+
+	txm := &starktxm{lggr: logger.Test(t), txStore: NewChainTxStore(), status: newStatusTracker()}
+	txs := []Tx{
+		{id: "expired", opts: TxOpts{Deadline: time.Now().Add(-time.Second)}},
+		{id: "live", opts: TxOpts{Deadline: time.Now().Add(time.Hour)}},
+		{id: "no deadline"},
+	}
+	for _, tx := range txs {
+		txm.status.queued(tx.id)
+	}
+
+	live := txm.dropExpired(logger.Test(t), txs)
+	require.Equal(t, 2, len(live))
+	assert.Equal(t, "live", live[0].id)
+	assert.Equal(t, "no deadline", live[1].id)
+
+	state, err := txm.GetTransactionStatus("expired")
+	require.NoError(t, err)
+	assert.Equal(t, TxStatusExpired, state.Status)
+	assert.True(t, state.Status.IsTerminal())
+	assert.NotEmpty(t, state.Error)
+}
+
+func TestRetryable(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, retryable(fmt.Errorf("failed to invoke tx: %w", starknet.ErrInvalidNonce)))
+	assert.True(t, retryable(starknet.ErrRateLimited))
+	assert.True(t, retryable(starknet.ErrNodeUnavailable))
+	assert.False(t, retryable(starknet.ErrInsufficientBalance))
+	assert.False(t, retryable(starknet.ErrContractNotDeployed))
+	assert.False(t, retryable(errors.New("boom")))
+}
+
+func TestNextBatch(t *testing.T) {
+	t.Parallel()
+
+	push := func(q *txQueue, n int) {
+		for i := 0; i < n; i++ {
+			_, err := q.Push(Tx{})
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		cfg := mocks.NewConfig(t)
+		cfg.On("BatchWindow").Return(time.Duration(0))
+		txm := &starktxm{cfg: cfg, stop: make(chan struct{})}
+
+		q := newTxQueue()
+		push(q, 3)
+		assert.Equal(t, 1, len(txm.nextBatch(q)))
+		assert.Equal(t, 2, q.Len())
+	})
+
+	t.Run("collects calls within window", func(t *testing.T) {
+		t.Parallel()
+		cfg := mocks.NewConfig(t)
+		cfg.On("BatchWindow").Return(100 * time.Millisecond)
+		cfg.On("MaxBatchSize").Return(3)
+		txm := &starktxm{cfg: cfg, stop: make(chan struct{})}
+
+		q := newTxQueue()
+		push(q, 1)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			push(q, 4)
+		}()
+		assert.Equal(t, 3, len(txm.nextBatch(q)))
+		assert.Equal(t, 2, q.Len())
+	})
+}
@@ -0,0 +1,134 @@
+package txm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/loop"
+	adapters "github.com/smartcontractkit/chainlink-common/pkg/loop/adapters/starknet"
+)
+
+// ErrSignerKeyNotFound is returned by the [RemoteKeystore] if the remote signer does not hold the key
+var ErrSignerKeyNotFound = errors.New("key not found in remote signer")
+
+// Remote signer HTTP API:
+//
+//	POST /sign      {"id": "<public key>", "hash": "<hex>"} -> {"r": "<hex>", "s": "<hex>"}
+//	GET  /accounts  -> {"accounts": ["<public key>", ...]}
+//
+// A sign request without a hash checks that the key exists and returns an empty signature. Unknown keys return
+// 404 Not Found, other failures a non 2xx status with {"error": "<message>"}.
+type (
+	signRequest struct {
+		ID   string `json:"id"`
+		Hash string `json:"hash,omitempty"`
+	}
+	signResponse struct {
+		R string `json:"r,omitempty"`
+		S string `json:"s,omitempty"`
+	}
+	accountsResponse struct {
+		Accounts []string `json:"accounts"`
+	}
+	signerError struct {
+		Error string `json:"error"`
+	}
+)
+
+var _ loop.Keystore = (*RemoteKeystore)(nil)
+
+// RemoteKeystore is a [loop.Keystore] that forwards Stark curve signing to an external signer process over HTTP,
+// so keys can be held by an HSM or MPC signer instead of the node. Signatures are encoded as [adapters.Signature]
+// bytes, as required by the [KeystoreAdapter].
+type RemoteKeystore struct {
+	url    *url.URL
+	client *http.Client
+}
+
+// NewRemoteKeystore returns a keystore for the signer at baseURL. The client carries the transport config,
+// e.g. mTLS and timeouts, [http.DefaultClient] is used if nil.
+func NewRemoteKeystore(baseURL *url.URL, client *http.Client) *RemoteKeystore {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &RemoteKeystore{url: baseURL, client: client}
+}
+
+// Sign implements [loop.Keystore]. The hash is the byte representation of a big.Int, a nil hash checks that the key exists.
+func (k *RemoteKeystore) Sign(ctx context.Context, id string, hash []byte) ([]byte, error) {
+	req := signRequest{ID: id}
+	if hash != nil {
+		req.Hash = "0x" + new(big.Int).SetBytes(hash).Text(16)
+	}
+	var res signResponse
+	if err := k.do(ctx, http.MethodPost, "sign", req, &res); err != nil {
+		return nil, fmt.Errorf("failed to sign with key %s: %w", id, err)
+	}
+	if hash == nil {
+		return nil, nil
+	}
+
+	r, ok := new(big.Int).SetString(res.R, 0)
+	if !ok {
+		return nil, fmt.Errorf("remote signer returned invalid signature r: %q", res.R)
+	}
+	s, ok := new(big.Int).SetString(res.S, 0)
+	if !ok {
+		return nil, fmt.Errorf("remote signer returned invalid signature s: %q", res.S)
+	}
+	sig, err := adapters.SignatureFromBigInts(r, s)
+	if err != nil {
+		return nil, err
+	}
+	return sig.Bytes()
+}
+
+// Accounts implements [loop.Keystore] and returns the public keys held by the signer
+func (k *RemoteKeystore) Accounts(ctx context.Context) ([]string, error) {
+	var res accountsResponse
+	if err := k.do(ctx, http.MethodGet, "accounts", nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	return res.Accounts, nil
+}
+
+func (k *RemoteKeystore) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, k.url.JoinPath(path).String(), reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return ErrSignerKeyNotFound
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		var serr signerError
+		if json.NewDecoder(res.Body).Decode(&serr) != nil || serr.Error == "" {
+			serr.Error = res.Status
+		}
+		return fmt.Errorf("remote signer error: %s", serr.Error)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,64 @@
+package txm_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/NethermindEth/starknet.go/curve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm"
+)
+
+func TestRemoteKeystore(t *testing.T) {
+	privateKey := big.NewInt(0x1234)
+	pubX, pubY, err := curve.Curve.PrivateToPoint(privateKey)
+	require.NoError(t, err)
+	publicKey := "0x" + pubX.Text(16)
+
+	server := httptest.NewServer(txm.NewLocalSigner(map[string]*big.Int{publicKey: privateKey}))
+	t.Cleanup(server.Close)
+	signerURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	ks := txm.NewRemoteKeystore(signerURL, server.Client())
+
+	t.Run("sign", func(t *testing.T) {
+		hash := big.NewInt(42)
+		r, s, err := txm.NewKeystoreAdapter(ks).Sign(context.Background(), publicKey, hash)
+		require.NoError(t, err)
+		assert.True(t, curve.Curve.Verify(hash, r, s, pubX, pubY))
+	})
+
+	t.Run("key exists", func(t *testing.T) {
+		sig, err := ks.Sign(context.Background(), publicKey, nil)
+		require.NoError(t, err)
+		assert.Nil(t, sig)
+
+		_, err = ks.Sign(context.Background(), "0x1", nil)
+		require.ErrorIs(t, err, txm.ErrSignerKeyNotFound)
+	})
+
+	t.Run("accounts", func(t *testing.T) {
+		accounts, err := ks.Accounts(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{publicKey}, accounts)
+	})
+
+	t.Run("signer error", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error": "hsm unavailable"}`))
+		}))
+		t.Cleanup(failing.Close)
+		failingURL, err := url.Parse(failing.URL)
+		require.NoError(t, err)
+
+		_, err = txm.NewRemoteKeystore(failingURL, nil).Sign(context.Background(), publicKey, big.NewInt(42).Bytes())
+		require.ErrorContains(t, err, "hsm unavailable")
+	})
+}
@@ -0,0 +1,48 @@
+package txm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	starknetaccount "github.com/NethermindEth/starknet.go/account"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+var ErrSimulationReverted = errors.New("tx reverted in simulation")
+
+// simulate runs the signed tx through starknet_simulateTransactions, including account validation and the
+// fee charge, and fails with [ErrSimulationReverted] if the execution reverts
+func simulate(ctx context.Context, account *starknetaccount.Account, tx starknetrpc.InvokeTxnV3) error {
+	res, err := account.SimulateTransactions(ctx, starknetrpc.BlockID{Tag: "latest"}, []starknetrpc.Transaction{tx}, []starknetrpc.SimulationFlag{})
+	if err != nil {
+		return fmt.Errorf("failed to simulate tx: %w", starknet.ClassifyError(err))
+	}
+	if len(res) != 1 {
+		return fmt.Errorf("failed to simulate tx: expected 1 result, got %d", len(res))
+	}
+	if reason, reverted := revertReason(res[0].TxnTrace); reverted {
+		return fmt.Errorf("%w: %s", ErrSimulationReverted, reason)
+	}
+	return nil
+}
+
+// revertReason returns the revert reason of an invoke trace, false if execution succeeded
+func revertReason(trace starknetrpc.TxnTrace) (string, bool) {
+	switch t := trace.(type) {
+	case starknetrpc.InvokeTxnTrace:
+		return t.ExecuteInvocation.RevertReason, t.ExecuteInvocation.RevertReason != ""
+	case map[string]any:
+		// traces are not typed by the provider and decode as generic maps
+		exec, ok := t["execute_invocation"].(map[string]any)
+		if !ok {
+			return "", false
+		}
+		reason, ok := exec["revert_reason"].(string)
+		return reason, ok
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,60 @@
+package txm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetaccount "github.com/NethermindEth/starknet.go/account"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+func TestSimulate(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		body string
+		err  error
+	}{
+		{
+			name: "success",
+			body: `{"jsonrpc": "2.0", "id": 1, "result": [{"transaction_trace": {"type": "INVOKE", "execute_invocation": {"contract_address": "0x1"}}, "fee_estimation": {}}]}`,
+		},
+		{
+			name: "reverted",
+			body: `{"jsonrpc": "2.0", "id": 1, "result": [{"transaction_trace": {"type": "INVOKE", "execute_invocation": {"revert_reason": "stale report"}}, "fee_estimation": {}}]}`,
+			err:  ErrSimulationReverted,
+		},
+		{
+			name: "validation failure",
+			body: `{"jsonrpc": "2.0", "id": 1, "error": {"code": 55, "message": "Account validation failed"}}`,
+			err:  starknet.ErrValidationFailure,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			provider := newTestProvider(t, map[string]string{
+				"starknet_chainId":              `{"jsonrpc": "2.0", "id": 1, "result": "0x534e5f474f45524c49"}`,
+				"starknet_simulateTransactions": tc.body,
+			})
+			account, err := starknetaccount.NewAccount(provider, new(felt.Felt).SetUint64(1), "0x1", nil, 2)
+			require.NoError(t, err)
+
+			err = simulate(context.Background(), account, starknetrpc.InvokeTxnV3{})
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.err)
+			if tc.err == ErrSimulationReverted {
+				assert.ErrorContains(t, err, "stale report")
+			}
+		})
+	}
+}
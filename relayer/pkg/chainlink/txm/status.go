@@ -0,0 +1,322 @@
+package txm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+)
+
+// TxState is the lifecycle state of an enqueued tx, as returned by [TxManager.GetTransactionStatus]
+type TxState struct {
+	ID     string
+	Status TxStatus
+	// Hash of the included attempt, or the latest attempt if none was included. Empty until broadcast.
+	Hash string
+	// Attempts is the number of times the tx was broadcast, including fee bumped replacements
+	Attempts int
+	TxOutcome
+	// Finalized is set once an included tx reached the finality target of the TXM
+	Finalized bool
+	// Error is set if the tx failed to broadcast, or why it was dropped before it was signed
+	Error     string
+	UpdatedAt time.Time
+}
+
+// maxPendingCallbacks bounds the status updates waiting for their callbacks, further updates are dropped
+const maxPendingCallbacks = 1000
+
+// statusTracker holds the state of txs that are not in the TxStore yet and notifies subscribers of status changes
+type statusTracker struct {
+	lock sync.Mutex
+	// queued, failed, discarded or dry run txs by id, removed once broadcast
+	local     map[string]TxState
+	callbacks map[string][]func(TxState)
+	// changed is closed and replaced on every update
+	changed chan struct{}
+	// updates waiting for their callbacks, run in order by the dispatch loop
+	pending []statusUpdate
+	// signals the dispatch loop that updates are pending
+	ready chan struct{}
+}
+
+type statusUpdate struct {
+	state     TxState
+	callbacks []func(TxState)
+}
+
+func newStatusTracker() *statusTracker {
+	return &statusTracker{
+		local:     map[string]TxState{},
+		callbacks: map[string][]func(TxState){},
+		changed:   make(chan struct{}),
+		ready:     make(chan struct{}, 1),
+	}
+}
+
+func (s *statusTracker) queued(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.local[id] = TxState{ID: id, Status: TxStatusQueued, UpdatedAt: time.Now()}
+}
+
+func (s *statusTracker) failed(id string, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.local[id] = TxState{ID: id, Status: TxStatusFailed, Error: err.Error(), UpdatedAt: time.Now()}
+}
+
+func (s *statusTracker) dryRun(id string, hash string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.local[id] = TxState{ID: id, Status: TxStatusDryRun, Hash: hash, UpdatedAt: time.Now()}
+}
+
+// discarded records a queued tx that was dropped before it was signed
+func (s *statusTracker) discarded(id string, status TxStatus, reason string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.local[id] = TxState{ID: id, Status: status, Error: reason, UpdatedAt: time.Now()}
+}
+
+// remove drops the local state of the tx, once broadcast the TxStore is the source of truth
+func (s *statusTracker) remove(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.local, id)
+}
+
+func (s *statusTracker) get(id string) (TxState, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	state, exists := s.local[id]
+	return state, exists
+}
+
+func (s *statusTracker) subscribe(id string, fn func(TxState)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.callbacks[id] = append(s.callbacks[id], fn)
+}
+
+// notify wakes up waiters and queues the callbacks of the tx, callbacks are dropped once the tx is terminal and finalized.
+// Callbacks run on the dispatch loop so that they can't stall the TXM, it returns false if the update was dropped
+// because too many updates are pending.
+func (s *statusTracker) notify(state TxState) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	close(s.changed)
+	s.changed = make(chan struct{})
+	callbacks := s.callbacks[state.ID]
+	if state.Status.IsTerminal() && !state.awaitingFinality() {
+		delete(s.callbacks, state.ID)
+	}
+	if len(callbacks) == 0 {
+		return true
+	}
+	if len(s.pending) >= maxPendingCallbacks {
+		return false
+	}
+	s.pending = append(s.pending, statusUpdate{state: state, callbacks: callbacks})
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// dispatchLoop runs the callbacks of the pending updates in order until stop is closed,
+// no callbacks are started after that
+func (s *statusTracker) dispatchLoop(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-s.ready:
+		}
+		for {
+			s.lock.Lock()
+			if len(s.pending) == 0 {
+				s.lock.Unlock()
+				break
+			}
+			update := s.pending[0]
+			s.pending = s.pending[1:]
+			s.lock.Unlock()
+
+			for _, fn := range update.callbacks {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				fn(update.state)
+			}
+		}
+	}
+}
+
+// expire drops the callbacks of txs that are no longer known, e.g. because they were pruned before reaching a final status
+func (s *statusTracker) expire(known func(id string) bool) {
+	s.lock.Lock()
+	ids := make([]string, 0, len(s.callbacks))
+	for id := range s.callbacks {
+		ids = append(ids, id)
+	}
+	s.lock.Unlock()
+
+	for _, id := range ids {
+		if !known(id) {
+			s.lock.Lock()
+			delete(s.callbacks, id)
+			s.lock.Unlock()
+		}
+	}
+}
+
+// wait returns a channel that is closed on the next update
+func (s *statusTracker) wait() <-chan struct{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.changed
+}
+
+// prune removes failed, discarded and dry run txs that were last updated before the cutoff
+func (s *statusTracker) prune(cutoff time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for id, state := range s.local {
+		if state.Status.IsTerminal() && state.UpdatedAt.Before(cutoff) {
+			delete(s.local, id)
+		}
+	}
+}
+
+// awaitingFinality returns true if the tx was included but has not reached the finality target yet
+func (s TxState) awaitingFinality() bool {
+	return (s.Status == TxStatusConfirmed || s.Status == TxStatusReverted) && !s.Finalized
+}
+
+// txState derives the lifecycle state of an enqueued tx from the records of its attempts
+func txState(id string, records []TxRecord) TxState {
+	state := TxState{ID: id, Attempts: len(records)}
+	var latest *TxRecord
+	for i := range records {
+		rec := &records[i]
+		if rec.Status.IsIncluded() {
+			latest = rec
+			break
+		}
+		if latest == nil || rec.CreatedAt.After(latest.CreatedAt) || (rec.CreatedAt.Equal(latest.CreatedAt) && rec.Attempt > latest.Attempt) {
+			latest = rec
+		}
+	}
+	if latest == nil {
+		return state
+	}
+	state.Status = latest.Status
+	state.Hash = latest.Hash
+	state.TxOutcome = latest.TxOutcome
+	state.UpdatedAt = latest.UpdatedAt
+	if state.Status == TxStatusReplaced {
+		// the included attempt was pruned
+		state.Status = TxStatusDropped
+	}
+	return state
+}
+
+// GetTransactionStatus returns the lifecycle state of a tx by the id returned from Enqueue
+func (txm *starktxm) GetTransactionStatus(id string) (TxState, error) {
+	if state, exists := txm.status.get(id); exists {
+		return state, nil
+	}
+	records, err := txm.txStore.GetByID(id)
+	if err != nil {
+		return TxState{}, err
+	}
+	state := txState(id, records)
+	state.Finalized = txm.finalized(state)
+	return state, nil
+}
+
+// GetUnfinalizedTransactions returns the state of txs that were included but have not reached the finality target yet.
+// Txs are final once included if the target is ACCEPTED_ON_L2.
+func (txm *starktxm) GetUnfinalizedTransactions() ([]TxState, error) {
+	if txm.cfg.FinalityTarget() != starknetrpc.TxnStatus_Accepted_On_L1 {
+		return nil, nil
+	}
+	records, err := txm.txStore.GetUnfinalized()
+	if err != nil {
+		return nil, err
+	}
+	var states []TxState
+	for _, rec := range records {
+		for _, id := range rec.IDs {
+			state, err := txm.GetTransactionStatus(id)
+			if err != nil {
+				return nil, err
+			}
+			if state.awaitingFinality() {
+				states = append(states, state)
+			}
+		}
+	}
+	return states, nil
+}
+
+// finalized returns true if the tx was included and reached the finality target
+func (txm *starktxm) finalized(state TxState) bool {
+	switch state.Status {
+	case TxStatusConfirmed, TxStatusReverted:
+		return txm.cfg.FinalityTarget() != starknetrpc.TxnStatus_Accepted_On_L1 || state.FinalityStatus == starknetrpc.TxnStatus_Accepted_On_L1
+	default:
+		return false
+	}
+}
+
+// WaitForStatus blocks until the tx reaches the status or a terminal status, and returns its state.
+// It returns [ErrTxNotFound] if the tx is unknown.
+func (txm *starktxm) WaitForStatus(ctx context.Context, id string, status TxStatus) (TxState, error) {
+	for {
+		// subscribe before reading so updates in between are not missed
+		changed := txm.status.wait()
+		state, err := txm.GetTransactionStatus(id)
+		if err != nil {
+			return state, err
+		}
+		if state.Status == status || state.Status.IsTerminal() {
+			return state, nil
+		}
+		select {
+		case <-ctx.Done():
+			return state, fmt.Errorf("waiting for tx (%s) to be %s: %w", id, status, ctx.Err())
+		case <-changed:
+		}
+	}
+}
+
+// OnStatusChange registers a callback that is called on every status change of the tx until it is terminal.
+// Callbacks run in order on a goroutine of their own until the TXM is closed. It returns [ErrTxNotFound] if the tx is unknown.
+func (txm *starktxm) OnStatusChange(id string, fn func(TxState)) error {
+	if _, err := txm.GetTransactionStatus(id); err != nil {
+		return err
+	}
+	txm.status.subscribe(id, fn)
+	return nil
+}
+
+// notifyStatus publishes the current state of each tx to waiters and callbacks
+func (txm *starktxm) notifyStatus(ids ...string) {
+	for _, id := range ids {
+		state, err := txm.GetTransactionStatus(id)
+		if err != nil {
+			txm.lggr.Errorw("failed to get tx status", "id", id, "error", err)
+			continue
+		}
+		if !txm.status.notify(state) {
+			txm.lggr.Errorw("too many pending status callbacks, dropping update", "id", id, "status", state.Status)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package txm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/chainlink/txm/mocks"
+)
+
+func TestTxState(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	for _, tc := range []struct {
+		name     string
+		records  []TxRecord
+		status   TxStatus
+		hash     string
+		attempts int
+	}{
+		{
+			name:     "unconfirmed",
+			records:  []TxRecord{{Hash: "0xa", Status: TxStatusUnconfirmed, CreatedAt: now}},
+			status:   TxStatusUnconfirmed,
+			hash:     "0xa",
+			attempts: 1,
+		},
+		{
+			name: "latest attempt",
+			records: []TxRecord{
+				{Hash: "0xa", Status: TxStatusUnconfirmed, CreatedAt: now},
+				{Hash: "0xb", Status: TxStatusUnconfirmed, CreatedAt: now.Add(time.Second), Attempt: 1},
+			},
+			status:   TxStatusUnconfirmed,
+			hash:     "0xb",
+			attempts: 2,
+		},
+		{
+			name: "confirmed attempt wins",
+			records: []TxRecord{
+				{Hash: "0xa", Status: TxStatusConfirmed, CreatedAt: now},
+				{Hash: "0xb", Status: TxStatusReplaced, CreatedAt: now.Add(time.Second), Attempt: 1},
+			},
+			status:   TxStatusConfirmed,
+			hash:     "0xa",
+			attempts: 2,
+		},
+		{
+			name:     "dropped",
+			records:  []TxRecord{{Hash: "0xa", Status: TxStatusDropped, CreatedAt: now}},
+			status:   TxStatusDropped,
+			hash:     "0xa",
+			attempts: 1,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			state := txState("id", tc.records)
+			assert.Equal(t, "id", state.ID)
+			assert.Equal(t, tc.status, state.Status)
+			assert.Equal(t, tc.hash, state.Hash)
+			assert.Equal(t, tc.attempts, state.Attempts)
+		})
+	}
+}
+
+func TestStarkTxm_WaitForStatus(t *testing.T) {
+	t.Parallel()
+
+	cfg := mocks.NewConfig(t)
+	cfg.On("FinalityTarget").Return(starknetrpc.TxnStatus_Accepted_On_L2)
+	txm := &starktxm{
+		lggr:    logger.Test(t),
+		cfg:     cfg,
+		txStore: NewChainTxStore(),
+		status:  newStatusTracker(),
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go txm.status.dispatchLoop(stop)
+	from := new(felt.Felt).SetUint64(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := txm.GetTransactionStatus("id")
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	// unknown txs can't be subscribed to
+	require.ErrorIs(t, txm.OnStatusChange("id", func(TxState) {}), ErrTxNotFound)
+
+	txm.status.queued("id")
+	updates := make(chan TxState, 3)
+	require.NoError(t, txm.OnStatusChange("id", func(state TxState) { updates <- state }))
+	txm.notifyStatus("id")
+	state, err := txm.WaitForStatus(ctx, "id", TxStatusQueued)
+	require.NoError(t, err)
+	assert.Equal(t, TxStatusQueued, state.Status)
+
+	done := make(chan TxState)
+	go func() {
+		state, err := txm.WaitForStatus(ctx, "id", TxStatusConfirmed)
+		assert.NoError(t, err)
+		done <- state
+	}()
+
+	// broadcast
+	txm.status.remove("id")
+	require.NoError(t, txm.txStore.Save(TxRecord{Hash: "0xa", IDs: []string{"id"}, AccountAddress: from, Nonce: new(felt.Felt)}))
+	txm.notifyStatus("id")
+
+	// confirm
+	outcome := TxOutcome{
+		FinalityStatus:  starknetrpc.TxnStatus_Accepted_On_L2,
+		ExecutionStatus: starknetrpc.TxnExecutionStatusSUCCEEDED,
+		ActualFee:       new(felt.Felt).SetUint64(10),
+	}
+	require.NoError(t, txm.txStore.Confirm(from, "0xa", outcome))
+	txm.notifyStatus("id")
+
+	state = <-done
+	assert.Equal(t, TxStatusConfirmed, state.Status)
+	assert.Equal(t, "0xa", state.Hash)
+	assert.Equal(t, outcome, state.TxOutcome)
+	assert.True(t, state.Finalized)
+
+	// callbacks run in order
+	for _, status := range []TxStatus{TxStatusQueued, TxStatusUnconfirmed, TxStatusConfirmed} {
+		assert.Equal(t, status, (<-updates).Status)
+	}
+
+	// terminal status is returned immediately
+	txm.status.failed("failed", errors.New("boom"))
+	state, err = txm.WaitForStatus(ctx, "failed", TxStatusConfirmed)
+	require.NoError(t, err)
+	assert.Equal(t, TxStatusFailed, state.Status)
+	assert.Equal(t, "boom", state.Error)
+
+	// unknown txs are rejected instead of waiting until the context is done
+	_, err = txm.WaitForStatus(ctx, "null", TxStatusConfirmed)
+	require.ErrorIs(t, err, ErrTxNotFound)
+	require.NoError(t, ctx.Err())
+
+	// known txs wait until the context is done
+	txm.status.queued("waiting")
+	waitCtx, waitCancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer waitCancel()
+	_, err = txm.WaitForStatus(waitCtx, "waiting", TxStatusConfirmed)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestStatusTracker_Callbacks(t *testing.T) {
+	t.Parallel()
+
+	s := newStatusTracker()
+	// blocking callbacks don't stall notifications
+	unblock := make(chan struct{})
+	calls := make(chan TxStatus, maxPendingCallbacks)
+	s.subscribe("id", func(state TxState) {
+		<-unblock
+		calls <- state.Status
+	})
+	for i := 0; i < maxPendingCallbacks; i++ {
+		require.True(t, s.notify(TxState{ID: "id", Status: TxStatusQueued}))
+	}
+	assert.False(t, s.notify(TxState{ID: "id", Status: TxStatusQueued}), "queue is full")
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		s.dispatchLoop(stop)
+	}()
+	close(unblock)
+	for i := 0; i < maxPendingCallbacks; i++ {
+		<-calls
+	}
+	require.True(t, s.notify(TxState{ID: "id", Status: TxStatusFailed}))
+	assert.Equal(t, TxStatusFailed, <-calls)
+	assert.Empty(t, s.callbacks, "dropped once terminal")
+
+	// no callbacks run once stopped
+	close(stop)
+	<-stopped
+	s.subscribe("closed", func(TxState) { calls <- TxStatusQueued })
+	require.True(t, s.notify(TxState{ID: "closed", Status: TxStatusQueued}))
+	select {
+	case <-calls:
+		t.Fatal("callback ran after stop")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// callbacks of unknown txs expire
+	s.subscribe("pruned", func(TxState) {})
+	s.subscribe("known", func(TxState) {})
+	s.expire(func(id string) bool { return id == "known" })
+	assert.Len(t, s.callbacks, 1)
+	assert.Contains(t, s.callbacks, "known")
+}
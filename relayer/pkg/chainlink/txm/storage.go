@@ -0,0 +1,460 @@
+package txm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+)
+
+var ErrTxNotFound = errors.New("tx not found")
+
+type TxStatus string
+
+const (
+	TxStatusQueued      TxStatus = "QUEUED"      // enqueued, not yet broadcast. Only held in memory
+	TxStatusFailed      TxStatus = "FAILED"      // failed to broadcast. Only held in memory
+	TxStatusDryRun      TxStatus = "DRY_RUN"     // signed and simulated but not submitted in dry run mode. Only held in memory
+	TxStatusExpired     TxStatus = "EXPIRED"     // dropped from the queue after its deadline. Only held in memory
+	TxStatusSuperseded  TxStatus = "SUPERSEDED"  // dropped from the queue for a newer tx with the same dedup key. Only held in memory
+	TxStatusUnconfirmed TxStatus = "UNCONFIRMED" // broadcast, waiting for inclusion
+	TxStatusConfirmed   TxStatus = "CONFIRMED"   // included on chain and executed successfully
+	TxStatusReverted    TxStatus = "REVERTED"    // included on chain but execution reverted, the fee was charged
+	TxStatusRejected    TxStatus = "REJECTED"    // rejected by the sequencer, the nonce was not used
+	TxStatusReplaced    TxStatus = "REPLACED"    // another attempt for the same nonce was confirmed
+	TxStatusDropped     TxStatus = "DROPPED"     // the nonce was used by a tx outside of the TXM
+)
+
+// IsTerminal returns true if the tx no longer needs to be tracked by the TXM
+func (s TxStatus) IsTerminal() bool {
+	return s != TxStatusQueued && s != TxStatusUnconfirmed
+}
+
+// IsIncluded returns true if the tx attempt was included on chain or rejected, i.e. its nonce is no longer pending
+func (s TxStatus) IsIncluded() bool {
+	return s == TxStatusConfirmed || s == TxStatusReverted || s == TxStatusRejected
+}
+
+// TxOutcome is the on-chain result of a tx, as recorded in its receipt
+type TxOutcome struct {
+	FinalityStatus  starknetrpc.TxnStatus          `json:"finality_status,omitempty"`
+	ExecutionStatus starknetrpc.TxnExecutionStatus `json:"execution_status,omitempty"`
+	// ActualFee is the fee charged by the sequencer, in FRI for V3 txs
+	ActualFee    *felt.Felt `json:"actual_fee,omitempty"`
+	RevertReason string     `json:"revert_reason,omitempty"`
+	BlockNumber  uint64     `json:"block_number,omitempty"`
+}
+
+// Status returns the status of a tx with the outcome
+func (o TxOutcome) Status() TxStatus {
+	switch {
+	case o.FinalityStatus == starknetrpc.TxnStatus_Rejected:
+		return TxStatusRejected
+	case o.ExecutionStatus == starknetrpc.TxnExecutionStatusREVERTED:
+		return TxStatusReverted
+	default:
+		return TxStatusConfirmed
+	}
+}
+
+// TxRecord is the persisted form of a single broadcast attempt
+type TxRecord struct {
+	Hash string `json:"hash"`
+	// Type is DEPLOY_ACCOUNT for account deploys, INVOKE or empty for invokes
+	Type starknetrpc.TransactionType `json:"type,omitempty"`
+	// IDs are the ids returned by Enqueue for the calls in the tx
+	IDs            []string                   `json:"ids"`
+	AccountAddress *felt.Felt                 `json:"account_address"`
+	PublicKey      *felt.Felt                 `json:"public_key"`
+	Nonce          *felt.Felt                 `json:"nonce"`
+	Calls          []starknetrpc.FunctionCall `json:"calls"`
+	// Attempt is 0 for the first broadcast of a nonce and incremented for each replacement
+	Attempt        int                               `json:"attempt"`
+	ResourceBounds starknetrpc.ResourceBoundsMapping `json:"resource_bounds"`
+	Tip            starknetrpc.U64                   `json:"tip"`
+	Status         TxStatus                          `json:"status"`
+	CreatedAt      time.Time                         `json:"created_at"`
+	UpdatedAt      time.Time                         `json:"updated_at"`
+	TxOutcome
+}
+
+// awaitingL1 returns true if the tx was executed on L2 but has not settled on L1 yet, rejected txs never settle
+func (rec TxRecord) awaitingL1() bool {
+	return (rec.Status == TxStatusConfirmed || rec.Status == TxStatusReverted) && rec.FinalityStatus != starknetrpc.TxnStatus_Accepted_On_L1
+}
+
+// prunable returns true if the tx is terminal and was last updated before the cutoff.
+// Included txs that didn't settle on L1 are only prunable if keepUnsettled is not set.
+func (rec TxRecord) prunable(cutoff time.Time, keepUnsettled bool) bool {
+	if keepUnsettled && rec.awaitingL1() {
+		return false
+	}
+	return rec.Status.IsTerminal() && rec.UpdatedAt.Before(cutoff)
+}
+
+// TxStorage is the backend used by the [ChainTxStore] to persist tx attempts
+type TxStorage interface {
+	// Put inserts or replaces the record with the same hash
+	Put(rec TxRecord) error
+	// Get returns the record for the hash or [ErrTxNotFound]
+	Get(hash string) (TxRecord, error)
+	// GetByID returns every attempt that includes the tx id or [ErrTxNotFound]
+	GetByID(id string) ([]TxRecord, error)
+	// Unconfirmed returns all records that are not in a terminal state
+	Unconfirmed() ([]TxRecord, error)
+	// Unfinalized returns all records that were included on chain but not accepted on L1 yet
+	Unfinalized() ([]TxRecord, error)
+	// Prune removes terminal records last updated before the cutoff and returns how many were removed.
+	// Records awaiting L1 are kept if keepUnsettled is set.
+	Prune(cutoff time.Time, keepUnsettled bool) (int, error)
+	Close() error
+}
+
+var _ TxStorage = (*memoryTxStorage)(nil)
+
+// memoryTxStorage is a non-durable [TxStorage], records are lost on restart
+type memoryTxStorage struct {
+	lock    sync.RWMutex
+	records map[string]TxRecord
+	ids     map[string][]string // map tx id to the hash of each attempt
+}
+
+func NewMemoryTxStorage() *memoryTxStorage {
+	return &memoryTxStorage{
+		records: map[string]TxRecord{},
+		ids:     map[string][]string{},
+	}
+}
+
+func (m *memoryTxStorage) Put(rec TxRecord) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, exists := m.records[rec.Hash]; !exists {
+		for _, id := range rec.IDs {
+			m.ids[id] = append(m.ids[id], rec.Hash)
+		}
+	}
+	m.records[rec.Hash] = rec
+	return nil
+}
+
+func (m *memoryTxStorage) GetByID(id string) ([]TxRecord, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	hashes, exists := m.ids[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrTxNotFound, id)
+	}
+	out := make([]TxRecord, 0, len(hashes))
+	for _, hash := range hashes {
+		out = append(out, m.records[hash])
+	}
+	return out, nil
+}
+
+// delete must be called with the write lock held
+func (m *memoryTxStorage) delete(hash string) {
+	for _, id := range m.records[hash].IDs {
+		m.ids[id] = slices.DeleteFunc(m.ids[id], func(h string) bool { return h == hash })
+		if len(m.ids[id]) == 0 {
+			delete(m.ids, id)
+		}
+	}
+	delete(m.records, hash)
+}
+
+func (m *memoryTxStorage) Get(hash string) (TxRecord, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	rec, exists := m.records[hash]
+	if !exists {
+		return TxRecord{}, fmt.Errorf("%w: %s", ErrTxNotFound, hash)
+	}
+	return rec, nil
+}
+
+func (m *memoryTxStorage) Unconfirmed() ([]TxRecord, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	out := []TxRecord{}
+	for _, rec := range m.records {
+		if !rec.Status.IsTerminal() {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryTxStorage) Unfinalized() ([]TxRecord, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	out := []TxRecord{}
+	for _, rec := range m.records {
+		if rec.awaitingL1() {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryTxStorage) Prune(cutoff time.Time, keepUnsettled bool) (int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var pruned int
+	for hash, rec := range m.records {
+		if rec.prunable(cutoff, keepUnsettled) {
+			m.delete(hash)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+func (m *memoryTxStorage) len() int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return len(m.records)
+}
+
+// snapshot returns a copy of all records
+func (m *memoryTxStorage) snapshot() []TxRecord {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	out := make([]TxRecord, 0, len(m.records))
+	for _, rec := range m.records {
+		out = append(out, rec)
+	}
+	return out
+}
+
+func (m *memoryTxStorage) Close() error {
+	return nil
+}
+
+var _ TxStorage = (*fileTxStorage)(nil)
+
+// fileTxStorage is a crash-safe [TxStorage] backed by an append-only journal file.
+// Every Put is appended as a single JSON line and fsync'd before returning. On open the journal
+// is replayed (a torn trailing line from a crash is discarded) and compacted into a new file
+// that is atomically swapped in. Pruning compacts the journal again once most of its lines are stale.
+type fileTxStorage struct {
+	lock  sync.Mutex
+	path  string
+	file  *os.File
+	lines int // number of records in the journal, including stale ones
+	mem   *memoryTxStorage
+	// records put while the journal is compacted, appended to the compacted journal before it is swapped in
+	compacting bool
+	tail       []TxRecord
+}
+
+// NewFileTxStorage opens (or creates) the journal at path and loads the stored records
+func NewFileTxStorage(path string) (*fileTxStorage, error) {
+	s := &fileTxStorage{
+		path: path,
+		mem:  NewMemoryTxStorage(),
+	}
+	if err := s.replay(); err != nil {
+		return nil, fmt.Errorf("failed to replay tx store journal %s: %w", path, err)
+	}
+	// records past the retention are pruned by the TXM, which knows the finality target
+	if err := s.compact(); err != nil {
+		return nil, fmt.Errorf("failed to compact tx store journal %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *fileTxStorage) open() error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	s.file = f
+	return nil
+}
+
+func (s *fileTxStorage) replay() error {
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(bytes.NewReader(raw))
+	for {
+		line, rerr := r.ReadBytes('\n')
+		if errors.Is(rerr, io.EOF) {
+			// a line without a trailing newline was never fully written
+			return nil
+		} else if rerr != nil {
+			return rerr
+		}
+
+		var rec TxRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("corrupt record: %w", err)
+		}
+		if err := s.mem.Put(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// compact rewrites the journal with only the latest state of each record. The snapshot is written without
+// holding the lock, records put meanwhile are appended to the new journal before it replaces the old one.
+func (s *fileTxStorage) compact() error {
+	s.lock.Lock()
+	if s.compacting {
+		s.lock.Unlock()
+		return nil
+	}
+	s.compacting = true
+	records := s.mem.snapshot()
+	s.lock.Unlock()
+
+	tmp := s.path + ".tmp"
+	f, err := writeJournal(tmp, records)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	tail := s.tail
+	s.compacting, s.tail = false, nil
+	if err != nil {
+		return err
+	}
+	return s.swap(f, tmp, len(records), tail)
+}
+
+// writeJournal writes the records to a new journal file and returns it open for appending
+func writeJournal(path string, records []TxRecord) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	for _, rec := range records {
+		if err = writeRecord(w, rec); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		return nil, errors.Join(err, f.Close(), os.Remove(path))
+	}
+	return f, nil
+}
+
+// swap appends the tail to the compacted journal and replaces the current journal with it.
+// It must be called with the lock held, the current journal is kept if it fails.
+func (s *fileTxStorage) swap(f *os.File, tmp string, lines int, tail []TxRecord) error {
+	var err error
+	for _, rec := range tail {
+		if err = writeRecord(f, rec); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	err = errors.Join(err, f.Close())
+	if err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+	if s.file != nil {
+		// the old journal was replaced, closing it can't lose records
+		_ = s.file.Close()
+	}
+	s.lines = lines + len(tail)
+	return errors.Join(syncDir(filepath.Dir(s.path)), s.open())
+}
+
+func (s *fileTxStorage) Put(rec TxRecord) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.compacting {
+		s.tail = append(s.tail, rec)
+	}
+	// the record is kept in memory even if it can't be written, so the tx is still tracked until a restart
+	if err := writeRecord(s.file, rec); err != nil {
+		return errors.Join(fmt.Errorf("failed to write tx record: %w", err), s.mem.Put(rec))
+	}
+	s.lines++
+	if err := s.file.Sync(); err != nil {
+		return errors.Join(fmt.Errorf("failed to sync tx store journal: %w", err), s.mem.Put(rec))
+	}
+	return s.mem.Put(rec)
+}
+
+func (s *fileTxStorage) Get(hash string) (TxRecord, error) {
+	return s.mem.Get(hash)
+}
+
+func (s *fileTxStorage) GetByID(id string) ([]TxRecord, error) {
+	return s.mem.GetByID(id)
+}
+
+func (s *fileTxStorage) Unconfirmed() ([]TxRecord, error) {
+	return s.mem.Unconfirmed()
+}
+
+func (s *fileTxStorage) Unfinalized() ([]TxRecord, error) {
+	return s.mem.Unfinalized()
+}
+
+// Prune removes the records from memory, the journal is compacted once more than half of its lines are stale
+func (s *fileTxStorage) Prune(cutoff time.Time, keepUnsettled bool) (int, error) {
+	s.lock.Lock()
+	pruned, err := s.mem.Prune(cutoff, keepUnsettled)
+	stale := err == nil && pruned > 0 && s.lines > 2*s.mem.len()
+	s.lock.Unlock()
+	if !stale {
+		return pruned, err
+	}
+
+	// keep appending to the old journal if compaction failed, it is retried on the next prune
+	if err := s.compact(); err != nil {
+		return pruned, fmt.Errorf("failed to compact tx store journal %s: %w", s.path, err)
+	}
+	return pruned, nil
+}
+
+func (s *fileTxStorage) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.file.Close()
+}
+
+func writeRecord(w io.Writer, rec TxRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	return errors.Join(d.Sync(), d.Close())
+}
@@ -0,0 +1,234 @@
+package txm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileTxStorage(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "txs.jsonl")
+	account := new(felt.Felt).SetUint64(100)
+	call := starknetrpc.FunctionCall{
+		ContractAddress:    new(felt.Felt).SetUint64(1),
+		EntryPointSelector: new(felt.Felt).SetUint64(2),
+		Calldata:           []*felt.Felt{new(felt.Felt).SetUint64(3)},
+	}
+
+	s, err := NewFileTxStorage(path)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.Put(TxRecord{
+			Hash:           "0x" + string(rune('a'+i)),
+			IDs:            []string{"id"},
+			AccountAddress: account,
+			Nonce:          new(felt.Felt).SetUint64(uint64(i)),
+			Calls:          []starknetrpc.FunctionCall{call},
+			Status:         TxStatusUnconfirmed,
+			UpdatedAt:      time.Now(),
+		}))
+	}
+	rec, err := s.Get("0xa")
+	require.NoError(t, err)
+	rec.Status = TxStatusConfirmed
+	rec.TxOutcome = TxOutcome{
+		FinalityStatus:  starknetrpc.TxnStatus_Accepted_On_L2,
+		ExecutionStatus: starknetrpc.TxnExecutionStatusREVERTED,
+		ActualFee:       new(felt.Felt).SetUint64(10),
+		RevertReason:    "reverted",
+	}
+	require.NoError(t, s.Put(rec))
+	_, err = s.Get("0xnull")
+	require.ErrorIs(t, err, ErrTxNotFound)
+	require.NoError(t, s.Close())
+
+	// simulate a crash in the middle of appending a record
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"hash":"0xtorn","sta`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// reopen + replay
+	s, err = NewFileTxStorage(path)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, s.Close()) }()
+
+	unconfirmed, err := s.Unconfirmed()
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(unconfirmed))
+
+	rec, err = s.Get("0xb")
+	require.NoError(t, err)
+	assert.Equal(t, account, rec.AccountAddress)
+	assert.Equal(t, new(felt.Felt).SetUint64(1), rec.Nonce)
+	assert.Equal(t, []starknetrpc.FunctionCall{call}, rec.Calls)
+
+	rec, err = s.Get("0xa")
+	require.NoError(t, err)
+	assert.Equal(t, TxStatusConfirmed, rec.Status)
+	assert.Equal(t, starknetrpc.TxnExecutionStatusREVERTED, rec.ExecutionStatus)
+	assert.Equal(t, "reverted", rec.RevertReason)
+	assert.Equal(t, new(felt.Felt).SetUint64(10), rec.ActualFee)
+
+	records, err := s.GetByID("id")
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(records))
+	_, err = s.GetByID("null")
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	_, err = s.Get("0xtorn")
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	// journal was compacted to one line per record
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, bytes.Count(raw, []byte("\n")))
+}
+
+// testRetention stands in for the configured TxStoreRetention
+const testRetention = 24 * time.Hour
+
+func TestFileTxStorage_Prune(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "txs.jsonl")
+	s, err := NewFileTxStorage(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Put(TxRecord{Hash: "0xold", IDs: []string{"old"}, Status: TxStatusConfirmed, UpdatedAt: time.Now().Add(-2 * testRetention)}))
+	require.NoError(t, s.Put(TxRecord{Hash: "0xrejected", Status: TxStatusRejected, UpdatedAt: time.Now().Add(-2 * testRetention)}))
+	require.NoError(t, s.Put(TxRecord{Hash: "0xnew", Status: TxStatusConfirmed, UpdatedAt: time.Now()}))
+	pruned, err := s.Prune(time.Now().Add(-testRetention), false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, pruned)
+	require.NoError(t, s.Close())
+
+	// pruned records are not restored from the journal
+
+	s, err = NewFileTxStorage(path)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, s.Close()) }()
+	_, err = s.Get("0xold")
+	require.ErrorIs(t, err, ErrTxNotFound)
+	_, err = s.GetByID("old")
+	require.ErrorIs(t, err, ErrTxNotFound)
+	_, err = s.Get("0xnew")
+	require.NoError(t, err)
+}
+
+func TestFileTxStorage_PruneRunning(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "txs.jsonl")
+	s, err := NewFileTxStorage(path)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, s.Close()) }()
+
+	old := time.Now().Add(-2 * testRetention)
+	for i := 0; i < 4; i++ {
+		rec := TxRecord{Hash: "0x" + string(rune('a'+i)), IDs: []string{"old"}, Status: TxStatusUnconfirmed, UpdatedAt: old}
+		require.NoError(t, s.Put(rec))
+		rec.Status = TxStatusConfirmed
+		rec.FinalityStatus = starknetrpc.TxnStatus_Accepted_On_L1
+		require.NoError(t, s.Put(rec))
+	}
+	require.NoError(t, s.Put(TxRecord{Hash: "0xpending", Status: TxStatusUnconfirmed, UpdatedAt: old}))
+	require.NoError(t, s.Put(TxRecord{Hash: "0xnew", Status: TxStatusConfirmed, UpdatedAt: time.Now()}))
+
+	pruned, err := s.Prune(time.Now().Add(-testRetention), true)
+	require.NoError(t, err)
+	assert.Equal(t, 4, pruned)
+	_, err = s.GetByID("old")
+	require.ErrorIs(t, err, ErrTxNotFound)
+	// unconfirmed and recent txs are kept
+	_, err = s.Get("0xpending")
+	require.NoError(t, err)
+	_, err = s.Get("0xnew")
+	require.NoError(t, err)
+
+	// the journal was compacted and is still appended to
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(raw, []byte("\n")))
+	require.NoError(t, s.Put(TxRecord{Hash: "0xlater", Status: TxStatusUnconfirmed, UpdatedAt: time.Now()}))
+	raw, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, bytes.Count(raw, []byte("\n")))
+
+	pruned, err = s.Prune(time.Now().Add(-testRetention), true)
+	require.NoError(t, err)
+	assert.Zero(t, pruned)
+}
+
+func TestFileTxStorage_PutWhileCompacting(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "txs.jsonl")
+	s, err := NewFileTxStorage(path)
+	require.NoError(t, err)
+
+	old := time.Now().Add(-2 * testRetention)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, s.Put(TxRecord{Hash: fmt.Sprintf("0xold%d", i), Status: TxStatusRejected, UpdatedAt: old}))
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, s.Put(TxRecord{Hash: fmt.Sprintf("0xnew%d", i), Status: TxStatusUnconfirmed, UpdatedAt: time.Now()}))
+		}
+	}()
+	pruned, err := s.Prune(time.Now().Add(-testRetention), true)
+	require.NoError(t, err)
+	assert.Equal(t, 100, pruned)
+	wg.Wait()
+	require.NoError(t, s.Close())
+
+	// every record put during the compaction is in the new journal
+	s, err = NewFileTxStorage(path)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, s.Close()) }()
+	unconfirmed, err := s.Unconfirmed()
+	require.NoError(t, err)
+	assert.Len(t, unconfirmed, 100)
+}
+
+func TestMemoryTxStorage_Prune(t *testing.T) {
+	t.Parallel()
+
+	s := NewMemoryTxStorage()
+	old := time.Now().Add(-2 * testRetention)
+	require.NoError(t, s.Put(TxRecord{Hash: "0xa", IDs: []string{"id"}, Status: TxStatusReverted, UpdatedAt: old, TxOutcome: TxOutcome{FinalityStatus: starknetrpc.TxnStatus_Accepted_On_L1}}))
+	require.NoError(t, s.Put(TxRecord{Hash: "0xb", IDs: []string{"id"}, Status: TxStatusUnconfirmed, UpdatedAt: old}))
+	require.NoError(t, s.Put(TxRecord{Hash: "0xc", IDs: []string{"l2"}, Status: TxStatusConfirmed, UpdatedAt: old, TxOutcome: TxOutcome{FinalityStatus: starknetrpc.TxnStatus_Accepted_On_L2}}))
+
+	// txs that did not settle on L1 are kept while they are tracked
+	pruned, err := s.Prune(time.Now().Add(-testRetention), true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+	records, err := s.GetByID("id")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "0xb", records[0].Hash)
+	_, err = s.Get("0xc")
+	require.NoError(t, err)
+
+	pruned, err = s.Prune(time.Now().Add(-testRetention), false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+	_, err = s.GetByID("l2")
+	require.ErrorIs(t, err, ErrTxNotFound)
+}
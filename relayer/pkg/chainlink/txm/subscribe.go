@@ -0,0 +1,130 @@
+package txm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	starknetutils "github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+const (
+	// txWatchTimeout is how long a tx status subscription waits for the tx to be included,
+	// after it the confirmer polls the tx once before subscribing again
+	txWatchTimeout = time.Minute
+	// txWatchBackoff is how long no new subscriptions are made after one failed, txs are polled meanwhile
+	txWatchBackoff = time.Minute
+	// maxWatchedTxs caps the tx status subscriptions open at once, further txs are polled
+	maxWatchedTxs = 32
+)
+
+// pushedTx is an unconfirmed tx whose final status was pushed
+type pushedTx struct {
+	addr *felt.Felt
+	hash string
+}
+
+// txWatcher tracks the txs whose status is followed over subscriptions, the confirmer doesn't poll them
+type txWatcher struct {
+	lock     sync.Mutex
+	watched  map[string]struct{}
+	failedAt time.Time
+}
+
+func newTxWatcher() *txWatcher {
+	return &txWatcher{watched: map[string]struct{}{}}
+}
+
+// add starts watching the tx, it returns false if the tx is already watched, [maxWatchedTxs] are watched
+// or subscriptions recently failed
+func (w *txWatcher) add(hash string) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if _, ok := w.watched[hash]; ok || len(w.watched) >= maxWatchedTxs || time.Since(w.failedAt) < txWatchBackoff {
+		return false
+	}
+	w.watched[hash] = struct{}{}
+	return true
+}
+
+func (w *txWatcher) watching(hash string) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, ok := w.watched[hash]
+	return ok
+}
+
+// remove stops watching the tx, failed backs off new subscriptions
+func (w *txWatcher) remove(hash string, failed bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	delete(w.watched, hash)
+	if failed {
+		w.failedAt = time.Now()
+	}
+}
+
+// pollTx returns true if the confirmer has to poll the tx. Txs that are not watched yet are polled once
+// while their status subscription is made, then they are left to the subscription until it ends.
+func (txm *starktxm) pollTx(ctx context.Context, client *starknet.Client, addr *felt.Felt, hash string) bool {
+	if !client.SubscriptionsEnabled() {
+		return true
+	}
+	if txm.watcher.watching(hash) {
+		return false
+	}
+	if txm.watcher.add(hash) {
+		txm.done.Add(1)
+		go txm.followTx(ctx, client, addr, hash)
+	}
+	return true
+}
+
+// followTx hands the tx to the confirmer once its final status is pushed
+func (txm *starktxm) followTx(ctx context.Context, client *starknet.Client, addr *felt.Felt, hash string) {
+	defer txm.done.Done()
+	ctx, cancel := context.WithTimeout(ctx, txWatchTimeout)
+	defer cancel()
+
+	f, err := starknetutils.HexToFelt(hash)
+	if err != nil {
+		txm.lggr.Errorw("invalid felt value", "hash", hash)
+		txm.watcher.remove(hash, false)
+		return
+	}
+	sub, err := client.SubscribeTransactionStatus(ctx, f)
+	if err != nil {
+		txm.lggr.Warnw("failed to subscribe to tx status, polling instead", "hash", hash, "error", err)
+		txm.watcher.remove(hash, true)
+		return
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case update := <-sub.C():
+			if update.Status.FinalityStatus == starknetrpc.TxnStatus_Received {
+				continue
+			}
+			txm.watcher.remove(hash, false)
+			select {
+			case txm.pushed <- pushedTx{addr: addr, hash: hash}:
+			case <-ctx.Done():
+			}
+			return
+		case err := <-sub.Err():
+			if err != nil {
+				txm.lggr.Warnw("tx status subscription failed, polling instead", "hash", hash, "error", err)
+			}
+			txm.watcher.remove(hash, err != nil)
+			return
+		case <-ctx.Done():
+			txm.watcher.remove(hash, false)
+			return
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package txm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+
+	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
+)
+
+func TestTxWatcher(t *testing.T) {
+	t.Parallel()
+
+	w := newTxWatcher()
+	assert.True(t, w.add("0x1"))
+	assert.False(t, w.add("0x1"), "already watched")
+	assert.True(t, w.watching("0x1"))
+
+	w.remove("0x1", false)
+	assert.False(t, w.watching("0x1"))
+	assert.True(t, w.add("0x1"))
+
+	// the number of watched txs is capped
+	for i := 1; i < maxWatchedTxs; i++ { // 0x1 is watched
+		assert.True(t, w.add(fmt.Sprintf("0x%x", 0x100+i)))
+	}
+	assert.False(t, w.add("0x2"))
+	w.remove("0x101", false)
+	assert.True(t, w.add("0x2"))
+	w.remove("0x2", false)
+
+	// failed subscriptions back off all txs
+	w.remove("0x1", true)
+	assert.False(t, w.add("0x1"))
+	assert.False(t, w.add("0x2"))
+}
+
+func TestStarkTxm_PollTx(t *testing.T) {
+	t.Parallel()
+
+	txm := &starktxm{lggr: logger.Test(t), watcher: newTxWatcher()}
+	// clients without a WebSocket URL poll every tx
+	client := starknet.NewPool("SN_TEST", nil, logger.Test(t), 0, 0, starknet.RetryConfig{}).Client()
+	for i := 0; i < 2; i++ {
+		assert.True(t, txm.pollTx(context.Background(), client, new(felt.Felt).SetUint64(1), "0x1"))
+	}
+	assert.False(t, txm.watcher.watching("0x1"))
+}
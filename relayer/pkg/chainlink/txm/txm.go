@@ -12,6 +12,7 @@ import (
 	starknetaccount "github.com/NethermindEth/starknet.go/account"
 	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
 	starknetutils "github.com/NethermindEth/starknet.go/utils"
+	"github.com/google/uuid"
 	"golang.org/x/exp/maps"
 
 	"github.com/smartcontractkit/chainlink-common/pkg/logger"
@@ -22,19 +23,57 @@ import (
 	"github.com/smartcontractkit/chainlink-starknet/relayer/pkg/starknet"
 )
 
+var ErrBatchGasLimit = errors.New("multicall exceeds max batch gas")
+
 const (
+	// MaxQueueLen is the maximum number of txs queued per account
 	MaxQueueLen = 1000
+	// MaxBroadcastRetries is the number of times a tx is requeued after a transient broadcast error
+	MaxBroadcastRetries = 5
+
+	clientRetryInterval = time.Second
 )
 
 type TxManager interface {
-	Enqueue(accountAddress *felt.Felt, publicKey *felt.Felt, txFn starknetrpc.FunctionCall) error
+	// Enqueue queues a call to be sent from the account and returns an id to track its status
+	Enqueue(accountAddress *felt.Felt, publicKey *felt.Felt, txFn starknetrpc.FunctionCall, opts TxOpts) (string, error)
+	// GetTransactionStatus returns the lifecycle state of an enqueued tx
+	GetTransactionStatus(id string) (TxState, error)
+	// WaitForStatus blocks until the enqueued tx reaches the status, or a terminal status. Unknown txs return [ErrTxNotFound]
+	WaitForStatus(ctx context.Context, id string, status TxStatus) (TxState, error)
+	// OnStatusChange registers a callback for every status change of the enqueued tx, or returns [ErrTxNotFound]
+	OnStatusChange(id string, fn func(TxState)) error
+	// GetUnfinalizedTransactions returns the enqueued txs that were included but have not reached the finality target
+	GetUnfinalizedTransactions() ([]TxState, error)
 	InflightCount() (int, int)
+	// Resync overwrites the local nonce of an enqueued account with the on-chain nonce, skipping past unconfirmed txs
+	Resync(ctx context.Context, accountAddress *felt.Felt) error
+	// EstimateFee returns the max fee in FRI the account would pay for a tx of the calls under the configured fee policy
+	EstimateFee(ctx context.Context, accountAddress *felt.Felt, publicKey *felt.Felt, calls []starknetrpc.FunctionCall) (*big.Int, error)
+}
+
+// TxOpts are optional settings of an enqueued tx
+type TxOpts struct {
+	// Deadline is the time after which the tx is dropped if it was not signed yet, zero never expires
+	Deadline time.Time
+	// DedupKey identifies txs that supersede each other, e.g. reports transmitted to the same contract.
+	// Queued txs are dropped once a newer tx with the same key is enqueued for the account, empty disables dedup.
+	DedupKey string
+}
+
+// CallKey returns a dedup key for calls to the same entry point of a contract
+func CallKey(call starknetrpc.FunctionCall) string {
+	return call.ContractAddress.String() + ":" + call.EntryPointSelector.String()
 }
 
 type Tx struct {
+	id             string
 	publicKey      *felt.Felt
 	accountAddress *felt.Felt
 	call           starknetrpc.FunctionCall
+	opts           TxOpts
+	// retries is the number of times the tx was requeued after a broadcast error
+	retries int
 }
 
 type StarkTXM interface {
@@ -47,24 +86,67 @@ type starktxm struct {
 	lggr    logger.Logger
 	done    sync.WaitGroup
 	stop    chan struct{}
-	queue   chan Tx
 	ks      KeystoreAdapter
 	cfg     Config
 	nonce   NonceManager
 
-	client  *utils.LazyLoad[*starknet.Client]
-	txStore *ChainTxStore
+	// accounts that have enqueued txs by address, workers are started once the txm is running
+	accounts     map[string]*txAccount
+	accountsLock sync.RWMutex
+	running      bool
+
+	// *sync.Mutex by address, held while checking for and sending the deploy of an account
+	deployLocks sync.Map
+
+	// classes of sender accounts by address, detected once per account
+	classes     map[string]AccountClass
+	classesLock sync.RWMutex
+
+	// last read fee token balance of each account by address, only set if the balance monitor is enabled
+	balances     map[string]*big.Int
+	balancesLock sync.RWMutex
+
+	client    *utils.LazyLoad[*starknet.Client]
+	txStore   *ChainTxStore
+	status    *statusTracker
+	paymaster Paymaster
+
+	// txs followed over status subscriptions, the confirmer checks them once their final status is pushed
+	watcher *txWatcher
+	pushed  chan pushedTx
 }
 
-func New(lggr logger.Logger, keystore loop.Keystore, cfg Config, getClient func() (*starknet.Client, error)) (StarkTXM, error) {
+// New creates a TXM. The paymaster sponsors the fees of all txs, if nil the paymaster set in the config is used, if any.
+func New(lggr logger.Logger, keystore loop.Keystore, cfg Config, getClient func() (*starknet.Client, error), paymaster Paymaster) (StarkTXM, error) {
+	if paymaster == nil {
+		paymaster = NewConfigPaymaster(cfg)
+	}
+
+	var storage TxStorage = NewMemoryTxStorage()
+	if path := cfg.TxStorePath(); path != "" {
+		fileStorage, err := NewFileTxStorage(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open tx store: %w", err)
+		}
+		storage = fileStorage
+	} else {
+		logger.Criticalw(lggr, "TxStorePath is not set, unconfirmed txs are only kept in memory and will be lost on restart")
+	}
+
 	txm := &starktxm{
-		lggr:    logger.Named(lggr, "StarknetTxm"),
-		queue:   make(chan Tx, MaxQueueLen),
-		stop:    make(chan struct{}),
-		client:  utils.NewLazyLoad(getClient),
-		ks:      NewKeystoreAdapter(keystore),
-		cfg:     cfg,
-		txStore: NewChainTxStore(),
+		lggr:      logger.Named(lggr, "StarknetTxm"),
+		stop:      make(chan struct{}),
+		client:    utils.NewLazyLoad(getClient),
+		ks:        NewKeystoreAdapter(keystore),
+		cfg:       cfg,
+		accounts:  map[string]*txAccount{},
+		classes:   map[string]AccountClass{},
+		balances:  map[string]*big.Int{},
+		txStore:   NewChainTxStoreWithStorage(storage),
+		status:    newStatusTracker(),
+		paymaster: paymaster,
+		watcher:   newTxWatcher(),
+		pushed:    make(chan pushedTx, 1),
 	}
 	txm.nonce = NewNonceManager(txm.lggr)
 
@@ -81,62 +163,239 @@ func (txm *starktxm) Start(ctx context.Context) error {
 			return err
 		}
 
-		txm.done.Add(2) // waitgroup: tx sender + confirmer
-		go txm.broadcastLoop()
+		// resume confirming txs that were broadcast before the last shutdown
+		restored, err := txm.txStore.Load()
+		if err != nil {
+			txm.lggr.Errorw("failed to restore some txs from TxStore", "error", err)
+		}
+		if restored > 0 {
+			txm.lggr.Infow("restored unconfirmed txs from TxStore", "count", restored)
+		}
+
+		txm.done.Add(3) // waitgroup: confirmer + nonce syncer + status callbacks
 		go txm.confirmLoop()
+		go txm.nonceSyncLoop()
+		go func() {
+			defer txm.done.Done()
+			txm.status.dispatchLoop(txm.stop)
+		}()
+		if txm.monitorBalances() {
+			txm.done.Add(1)
+			go txm.balanceLoop()
+		}
+
+		// start tx senders for accounts that enqueued before start
+		txm.accountsLock.Lock()
+		defer txm.accountsLock.Unlock()
+		txm.running = true
+		for _, acc := range txm.accounts {
+			txm.done.Add(1)
+			go txm.broadcastLoop(acc)
+		}
 		return nil
 	})
 }
 
-func (txm *starktxm) broadcastLoop() {
+// broadcastLoop sends the queued txs of a single account
+func (txm *starktxm) broadcastLoop(acc *txAccount) {
 	defer txm.done.Done()
 
 	ctx, cancel := utils.ContextFromChan(txm.stop)
 	defer cancel()
 
-	txm.lggr.Debugw("broadcastLoop: started")
+	lggr := logger.With(txm.lggr, "account", acc.address)
+	lggr.Debugw("broadcastLoop: started")
 	for {
 		select {
 		case <-txm.stop:
-			txm.lggr.Debugw("broadcastLoop: stopped")
+			lggr.Debugw("broadcastLoop: stopped")
 			return
-		default:
-			// skip processing if queue is empty
-			if len(txm.queue) == 0 {
-				continue
-			}
+		case <-acc.queue.Signal():
+		}
 
+		for acc.queue.Len() > 0 {
 			// preserve tx queue: don't pull tx from queue until client is known to work
 			if _, err := txm.client.Get(); err != nil {
-				txm.lggr.Errorw("failed to fetch client: skipping processing tx", "error", err)
+				lggr.Errorw("failed to fetch client: skipping processing tx", "error", err)
+				select {
+				case <-txm.stop:
+					lggr.Debugw("broadcastLoop: stopped")
+					return
+				case <-time.After(clientRetryInterval):
+				}
+				continue
+			}
+			if txm.txStore.Deploying(acc.address) {
+				// txs from the account fail validation until it is deployed
+				lggr.Debugw("waiting for account deploy to be included")
+				select {
+				case <-txm.stop:
+					lggr.Debugw("broadcastLoop: stopped")
+					return
+				case <-time.After(txm.cfg.ConfirmationPoll()):
+				}
+				continue
+			}
+			if err := txm.gated(acc.address); err != nil {
+				// txs stay queued until the account is funded
+				lggr.Errorw("holding back queued txs until the account is funded", "error", err, "queued", acc.queue.Len())
+				select {
+				case <-txm.stop:
+					lggr.Debugw("broadcastLoop: stopped")
+					return
+				case <-time.After(txm.cfg.BalancePollInterval()):
+				}
+				continue
+			}
+			txs := txm.nextBatch(acc.queue)
+			acc.observeQueueDepth()
+			if len(txs) == 0 {
+				break
+			}
+			if txs = txm.dropExpired(lggr, txs); len(txs) == 0 {
 				continue
 			}
-			tx := <-txm.queue
 
 			// broadcast tx serially - wait until accepted by mempool before processing next
-			hash, err := txm.broadcast(ctx, tx.publicKey, tx.accountAddress, tx.call)
-			if err != nil {
-				txm.lggr.Errorw("transaction failed to broadcast", "error", err, "tx", tx.call)
-			} else {
-				txm.lggr.Infow("transaction broadcast", "txhash", hash)
+			if backoff := txm.broadcastBatch(ctx, lggr, acc, txs); backoff > 0 {
+				select {
+				case <-txm.stop:
+					lggr.Debugw("broadcastLoop: stopped")
+					return
+				case <-time.After(backoff):
+				}
 			}
 		}
 	}
 }
 
-const FEE_MARGIN uint32 = 115
+// nextBatch pops the next tx from the queue. If batching is enabled, calls queued within the batch window
+// are popped along with it, up to the max batch size.
+func (txm *starktxm) nextBatch(q *txQueue) []Tx {
+	window := txm.cfg.BatchWindow()
+	if window <= 0 {
+		return q.PopN(1)
+	}
+
+	size := txm.cfg.MaxBatchSize()
+	if q.Len() < size {
+		select {
+		case <-txm.stop:
+		case <-time.After(window):
+		}
+	}
+	return q.PopN(size)
+}
+
+// broadcastBatch sends the calls of the txs as a single multicall, splitting it if it exceeds the max batch gas.
+// If the broadcast failed with a transient error the txs are requeued, and the returned backoff is how long
+// the worker should wait before retrying.
+func (txm *starktxm) broadcastBatch(ctx context.Context, lggr logger.Logger, acc *txAccount, txs []Tx) (backoff time.Duration) {
+	ids := make([]string, len(txs))
+	calls := make([]starknetrpc.FunctionCall, len(txs))
+	for i := range txs {
+		ids[i] = txs[i].id
+		calls[i] = txs[i].call
+	}
+
+	start := time.Now()
+	hash, err := txm.broadcast(ctx, acc.publicKey, acc.address, ids, calls)
+	if errors.Is(err, starknet.ErrInvalidNonce) {
+		// local nonce drifted from chain: resync and retry once
+		acc.nonceErrors.Add(1)
+		lggr.Warnw("transaction failed to broadcast with nonce error, resyncing", "error", err)
+		if serr := txm.Resync(ctx, acc.address); serr != nil {
+			lggr.Errorw("failed to resync nonce", "error", serr)
+		} else {
+			hash, err = txm.broadcast(ctx, acc.publicKey, acc.address, ids, calls)
+		}
+	}
+	if errors.Is(err, ErrBatchGasLimit) {
+		lggr.Debugw("splitting batch that exceeds max batch gas", "calls", len(calls), "error", err)
+		return max(
+			txm.broadcastBatch(ctx, lggr, acc, txs[:len(txs)/2]),
+			txm.broadcastBatch(ctx, lggr, acc, txs[len(txs)/2:]),
+		)
+	}
+	if err != nil && retryable(err) && txs[0].retries < MaxBroadcastRetries {
+		for i := range txs {
+			txs[i].retries++
+		}
+		txm.supersede(lggr, acc.queue.PushFront(txs...))
+		acc.observeQueueDepth()
+		backoff = clientRetryInterval << (txs[0].retries - 1)
+		lggr.Warnw("transaction failed to broadcast, requeued", "error", err, "ids", ids, "retries", txs[0].retries, "backoff", backoff)
+		return backoff
+	}
+	dryRun := err == nil && txm.cfg.DryRun()
+	for _, id := range ids {
+		switch {
+		case err != nil:
+			txm.status.failed(id, err)
+		case dryRun:
+			txm.status.dryRun(id, hash)
+		default:
+			txm.status.remove(id)
+		}
+	}
+	if err == nil {
+		acc.nonceErrors.Store(0)
+		promBroadcastDuration.WithLabelValues(acc.chainID, acc.address.String()).Observe(time.Since(start).Seconds())
+	}
+	if err != nil {
+		lggr.Errorw("transaction failed to broadcast, dropped", "error", err, "errorClass", starknet.ErrorClass(err), "tx", calls, "ids", ids)
+	} else {
+		lggr.Infow("transaction broadcast", "txhash", hash, "calls", len(calls), "ids", ids)
+	}
+	txm.notifyStatus(ids...)
+	return 0
+}
+
+// dropExpired removes the txs that are past their deadline from the batch, before they are signed
+func (txm *starktxm) dropExpired(lggr logger.Logger, txs []Tx) []Tx {
+	now := time.Now()
+	live := make([]Tx, 0, len(txs))
+	for _, tx := range txs {
+		if tx.opts.Deadline.IsZero() || now.Before(tx.opts.Deadline) {
+			live = append(live, tx)
+			continue
+		}
+		lggr.Warnw("dropping expired tx", "id", tx.id, "deadline", tx.opts.Deadline, "tx", tx.call)
+		txm.status.discarded(tx.id, TxStatusExpired, fmt.Sprintf("deadline %s passed before the tx was signed", tx.opts.Deadline))
+		txm.notifyStatus(tx.id)
+	}
+	return live
+}
+
+// supersede drops queued txs that were replaced by a newer tx with the same dedup key
+func (txm *starktxm) supersede(lggr logger.Logger, txs []Tx) {
+	for _, tx := range txs {
+		lggr.Infow("dropping superseded tx", "id", tx.id, "dedupKey", tx.opts.DedupKey)
+		txm.status.discarded(tx.id, TxStatusSuperseded, fmt.Sprintf("superseded by a newer tx with dedup key %s", tx.opts.DedupKey))
+		txm.notifyStatus(tx.id)
+	}
+}
+
+// retryable returns true if a broadcast error is transient and the txs should be requeued. Txs that failed
+// for any other reason, e.g. insufficient balance, failed validation or an undeployed contract, are dropped.
+func retryable(err error) bool {
+	switch starknet.ErrorClass(err) {
+	case starknet.ErrInvalidNonce, starknet.ErrRateLimited, starknet.ErrNodeUnavailable:
+		return true
+	default:
+		return false
+	}
+}
 
-func (txm *starktxm) broadcast(ctx context.Context, publicKey *felt.Felt, accountAddress *felt.Felt, call starknetrpc.FunctionCall) (txhash string, err error) {
+func (txm *starktxm) broadcast(ctx context.Context, publicKey *felt.Felt, accountAddress *felt.Felt, ids []string, calls []starknetrpc.FunctionCall) (txhash string, err error) {
 	client, err := txm.client.Get()
 	if err != nil {
 		txm.client.Reset()
 		return txhash, fmt.Errorf("broadcast: failed to fetch client: %+w", err)
 	}
-	// create new account
-	cairoVersion := 2
-	account, err := starknetaccount.NewAccount(client.Provider, accountAddress, publicKey.String(), txm.ks, cairoVersion)
+	account, err := txm.newAccount(ctx, client, accountAddress, publicKey)
 	if err != nil {
-		return txhash, fmt.Errorf("failed to create new account: %+w", err)
+		return txhash, err
 	}
 
 	chainID, err := client.Provider.ChainID(ctx)
@@ -144,18 +403,194 @@ func (txm *starktxm) broadcast(ctx context.Context, publicKey *felt.Felt, accoun
 		return txhash, fmt.Errorf("failed to get chainID: %+w", err)
 	}
 
+	unlock := txm.lockAccount(accountAddress)
+	defer unlock()
+
 	nonce, err := txm.nonce.NextSequence(publicKey, chainID)
 	if err != nil {
 		return txhash, fmt.Errorf("failed to get nonce: %+w", err)
 	}
 
-	tx := starknetrpc.InvokeTxnV3{
+	rec, err := txm.sendInvoke(ctx, chainID, account, nonce, calls, nil)
+	if err != nil {
+		return txhash, err
+	}
+	if txm.cfg.DryRun() {
+		// the nonce was not used
+		return rec.Hash, nil
+	}
+
+	// update nonce if transaction is successful
+	txhash = rec.Hash
+	rec.IDs = ids
+	rec.AccountAddress = accountAddress
+	rec.PublicKey = publicKey
+	// the tx was sent, failing to track it must not report it as failed
+	if err := errors.Join(
+		txm.nonce.IncrementNextSequence(publicKey, chainID, nonce),
+		txm.txStore.Save(rec),
+	); err != nil {
+		txm.lggr.Errorw("transaction was broadcast but failed to be stored", "txhash", txhash, "ids", ids, "error", err)
+	}
+	return txhash, nil
+}
+
+// resubmit replaces a stuck tx with a new attempt for the same nonce with bumped resource bounds
+func (txm *starktxm) resubmit(ctx context.Context, prev TxRecord) (txhash string, err error) {
+	client, err := txm.client.Get()
+	if err != nil {
+		txm.client.Reset()
+		return txhash, fmt.Errorf("resubmit: failed to fetch client: %+w", err)
+	}
+	account, err := txm.newAccount(ctx, client, prev.AccountAddress, prev.PublicKey)
+	if err != nil {
+		return txhash, err
+	}
+
+	chainID, err := client.Provider.ChainID(ctx)
+	if err != nil {
+		return txhash, fmt.Errorf("failed to get chainID: %+w", err)
+	}
+
+	unlock := txm.lockAccount(prev.AccountAddress)
+	defer unlock()
+
+	rec, err := txm.sendInvoke(ctx, chainID, account, prev.Nonce, prev.Calls, &prev)
+	if err != nil {
+		return txhash, err
+	}
+	rec.IDs = prev.IDs
+	rec.AccountAddress = prev.AccountAddress
+	rec.PublicKey = prev.PublicKey
+	rec.Attempt = prev.Attempt + 1
+	if err := txm.txStore.SaveAttempt(rec); err != nil {
+		return rec.Hash, err
+	}
+	txm.notifyStatus(rec.IDs...)
+	return rec.Hash, nil
+}
+
+// lockAccount holds the send lock of the account, accounts that have not enqueued txs do not need to be locked
+func (txm *starktxm) lockAccount(address *felt.Felt) (unlock func()) {
+	txm.accountsLock.RLock()
+	acc, exists := txm.accounts[address.String()]
+	txm.accountsLock.RUnlock()
+	if !exists {
+		return func() {}
+	}
+	acc.sendLock.Lock()
+	return acc.sendLock.Unlock
+}
+
+// newAccount returns the account with the calldata format of its detected class
+func (txm *starktxm) newAccount(ctx context.Context, client *starknet.Client, accountAddress *felt.Felt, publicKey *felt.Felt) (*starknetaccount.Account, error) {
+	class, err := txm.accountClass(ctx, client.Provider, accountAddress)
+	if err != nil {
+		return nil, err
+	}
+	account, err := starknetaccount.NewAccount(client.Provider, accountAddress, publicKey.String(), txm.ks, class.CairoVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new account: %+w", err)
+	}
+	return account, nil
+}
+
+// sendInvoke builds, signs and sends an invoke tx with the given nonce. If prev is set, the resource bounds
+// are bumped by at least [FeeBumpPercent] over the previous attempt so it can replace it in the mempool.
+// The returned record only contains the tx fields, sender details are filled in by the caller.
+func (txm *starktxm) sendInvoke(ctx context.Context, chainID string, account *starknetaccount.Account, nonce *felt.Felt, calls []starknetrpc.FunctionCall, prev *TxRecord) (rec TxRecord, err error) {
+	policy := NewFeePolicy(txm.cfg)
+	tx, err := txm.newInvoke(ctx, account, policy, nonce, calls)
+	if err != nil {
+		return rec, err
+	}
+
+	// get fee for tx
+	// optional - pass nonce to fee estimate (if nonce gets ahead, estimate may fail)
+	// can we estimate fee without calling estimate - tbd with 1.0
+	friEstimate, err := txm.estimateFee(ctx, chainID, account, tx)
+	if err != nil {
+		return rec, err
+	}
+
+	// replacements keep the calls of the stuck tx, only new batches are limited
+	gasConsumed := friEstimate.GasConsumed.BigInt(new(big.Int))
+	if len(calls) > 1 && prev == nil {
+		if limit := txm.cfg.MaxBatchGas(); limit > 0 && gasConsumed.Cmp(new(big.Int).SetUint64(limit)) > 0 {
+			return rec, fmt.Errorf("%w: estimated %s > %d", ErrBatchGasLimit, gasConsumed, limit)
+		}
+	}
+
+	tx.ResourceBounds, tx.Tip, err = policy.ResourceBounds(friEstimate)
+	if err != nil {
+		return rec, err
+	}
+	if prev != nil {
+		tx.ResourceBounds, tx.Tip = bumpResourceBounds(tx.ResourceBounds, tx.Tip, prev.ResourceBounds, prev.Tip)
+		if err := policy.CheckBudget(tx.ResourceBounds, tx.Tip); err != nil {
+			return rec, fmt.Errorf("failed to bump fee: %w", err)
+		}
+	}
+
+	txm.lggr.Infow("Set resource bounds", "L1MaxAmount", tx.ResourceBounds.L1Gas.MaxAmount, "L1MaxPricePerUnit", tx.ResourceBounds.L1Gas.MaxPricePerUnit,
+		"L2MaxAmount", tx.ResourceBounds.L2Gas.MaxAmount, "L2MaxPricePerUnit", tx.ResourceBounds.L2Gas.MaxPricePerUnit, "Tip", tx.Tip)
+
+	// Re-sign transaction now that we've determined MaxFee
+	hash, err := signInvoke(ctx, account, &tx)
+	if err != nil {
+		return rec, err
+	}
+
+	execCtx, execCancel := context.WithTimeout(ctx, txm.cfg.TxTimeout())
+	defer execCancel()
+
+	// check the tx succeeds before paying for it
+	if txm.cfg.SimulateTxs() || txm.cfg.DryRun() {
+		if err := simulate(execCtx, account, tx); err != nil {
+			return rec, err
+		}
+	}
+	if txm.cfg.DryRun() {
+		txm.lggr.Infow("dry run: tx not submitted", "txhash", hash, "nonce", nonce, "calls", len(calls))
+		return TxRecord{
+			Hash:           hash.String(),
+			Type:           starknetrpc.TransactionType_Invoke,
+			Nonce:          nonce,
+			Calls:          calls,
+			ResourceBounds: tx.ResourceBounds,
+			Tip:            tx.Tip,
+		}, nil
+	}
+
+	// finally, transmit the invoke
+	res, err := account.AddInvokeTransaction(execCtx, tx)
+	if err != nil {
+		return rec, fmt.Errorf("failed to invoke tx: %+w", starknet.ClassifyError(err))
+	}
+	// handle nil pointer
+	if res == nil {
+		return rec, errors.New("execute response and error are nil")
+	}
+
+	return TxRecord{
+		Hash:           res.TransactionHash.String(),
+		Type:           starknetrpc.TransactionType_Invoke,
+		Nonce:          nonce,
+		Calls:          calls,
+		ResourceBounds: tx.ResourceBounds,
+		Tip:            tx.Tip,
+	}, nil
+}
+
+// newInvoke builds and signs an invoke tx of the calls with empty resource bounds, ready for fee estimation
+func (txm *starktxm) newInvoke(ctx context.Context, account *starknetaccount.Account, policy FeePolicy, nonce *felt.Felt, calls []starknetrpc.FunctionCall) (tx starknetrpc.InvokeTxnV3, err error) {
+	tx = starknetrpc.InvokeTxnV3{
 		Type:          starknetrpc.TransactionType_Invoke,
 		SenderAddress: account.AccountAddress,
 		Version:       starknetrpc.TransactionV3,
 		Signature:     []*felt.Felt{},
 		Nonce:         nonce,
-		ResourceBounds: starknetrpc.ResourceBoundsMapping{ // TODO: use proper values
+		ResourceBounds: starknetrpc.ResourceBoundsMapping{ // set from the fee estimate
 			L1Gas: starknetrpc.ResourceBounds{
 				MaxAmount:       "0x0",
 				MaxPricePerUnit: "0x0",
@@ -168,37 +603,62 @@ func (txm *starktxm) broadcast(ctx context.Context, publicKey *felt.Felt, accoun
 		Tip:                   "0x0",
 		PayMasterData:         []*felt.Felt{},
 		AccountDeploymentData: []*felt.Felt{},
-		NonceDataMode:         starknetrpc.DAModeL1, // TODO: confirm
-		FeeMode:               starknetrpc.DAModeL1, // TODO: confirm
+		NonceDataMode:         policy.NonceDAMode,
+		FeeMode:               policy.FeeDAMode,
+	}
+
+	tx.PayMasterData, tx.FeeMode, err = txm.sponsor(ctx, account.AccountAddress, calls, tx.FeeMode)
+	if err != nil {
+		return tx, err
 	}
 
 	// Building the Calldata with the help of FmtCalldata where we pass in the FnCall struct along with the Cairo version
-	tx.Calldata, err = account.FmtCalldata([]starknetrpc.FunctionCall{call})
+	tx.Calldata, err = account.FmtCalldata(calls)
 	if err != nil {
-		return txhash, err
+		return tx, err
 	}
 
 	// TODO: if we estimate with sig then the hash changes and we have to re-sign
 	// if we don't then the signature is invalid??
+	_, err = signInvoke(ctx, account, &tx)
+	return tx, err
+}
 
-	// TODO: SignInvokeTransaction for V3 is missing so we do it by hand
-	hash, err := account.TransactionHashInvoke(tx)
+// TODO: SignInvokeTransaction for V3 is missing so we do it by hand
+func signInvoke(ctx context.Context, account *starknetaccount.Account, tx *starknetrpc.InvokeTxnV3) (*felt.Felt, error) {
+	hash, err := account.TransactionHashInvoke(*tx)
 	if err != nil {
-		return txhash, err
+		return nil, err
 	}
-	signature, err := account.Sign(ctx, hash)
+	tx.Signature, err = account.Sign(ctx, hash)
+	return hash, err
+}
+
+// sponsor returns the paymaster data and fee mode of a tx, no paymaster data and the given fee mode if no paymaster is set
+func (txm *starktxm) sponsor(ctx context.Context, sender *felt.Felt, calls []starknetrpc.FunctionCall, feeMode starknetrpc.DataAvailabilityMode) ([]*felt.Felt, starknetrpc.DataAvailabilityMode, error) {
+	if txm.paymaster == nil {
+		return []*felt.Felt{}, feeMode, nil
+	}
+	data, mode, err := txm.paymaster.PaymasterData(ctx, sender, calls)
 	if err != nil {
-		return txhash, err
+		return nil, "", fmt.Errorf("failed to get paymaster data: %w", err)
 	}
-	tx.Signature = signature
+	if data == nil {
+		data = []*felt.Felt{}
+	}
+	if mode == "" {
+		mode = feeMode
+	}
+	return data, mode, nil
+}
 
-	// get fee for tx
-	// optional - pass nonce to fee estimate (if nonce gets ahead, estimate may fail)
-	// can we estimate fee without calling estimate - tbd with 1.0
+// estimateFee returns the FRI fee estimate of a signed tx
+func (txm *starktxm) estimateFee(ctx context.Context, chainID string, account *starknetaccount.Account, tx starknetrpc.BroadcastTxn) (starknetrpc.FeeEstimate, error) {
 	simFlags := []starknetrpc.SimulationFlag{}
 	feeEstimate, err := account.EstimateFee(ctx, []starknetrpc.BroadcastTxn{tx}, simFlags, starknetrpc.BlockID{Tag: "latest"})
 	if err != nil {
-		return txhash, fmt.Errorf("failed to estimate fee: %+w", err)
+		promEstimateFeeFailures.WithLabelValues(chainID, account.AccountAddress.String()).Inc()
+		return starknetrpc.FeeEstimate{}, fmt.Errorf("failed to estimate fee: %+w", starknet.ClassifyError(err))
 	}
 
 	txm.lggr.Infow("Account", "account", account.AccountAddress)
@@ -211,54 +671,9 @@ func (txm *starktxm) broadcast(ctx context.Context, publicKey *felt.Felt, accoun
 		}
 	}
 	if friEstimate == nil {
-		return txhash, fmt.Errorf("failed to get FRI estimate")
-	}
-
-	// TODO: does v3 tx uses fri instead of wei? check feeEstimate[0].FeeUnit?
-	// pad estimate to 110%
-	gasConsumed := friEstimate.GasConsumed.BigInt(new(big.Int))
-	expandedGas := new(big.Int).Mul(gasConsumed, big.NewInt(140))
-	maxGas := new(big.Int).Div(expandedGas, big.NewInt(100))
-	tx.ResourceBounds.L2Gas.MaxAmount = starknetrpc.U64(starknetutils.BigIntToFelt(maxGas).String())
-
-	// TODO: add margin
-	tx.ResourceBounds.L2Gas.MaxPricePerUnit = starknetrpc.U128(friEstimate.GasPrice.String())
-
-	txm.lggr.Infow("Set resource bounds", "L2MaxAmount", tx.ResourceBounds.L2Gas.MaxAmount, "L2MaxPricePerUnit", tx.ResourceBounds.L2Gas.MaxPricePerUnit)
-
-	// Re-sign transaction now that we've determined MaxFee
-	// TODO: SignInvokeTransaction for V3 is missing so we do it by hand
-	hash, err = account.TransactionHashInvoke(tx)
-	if err != nil {
-		return txhash, err
+		return starknetrpc.FeeEstimate{}, fmt.Errorf("failed to get FRI estimate")
 	}
-	signature, err = account.Sign(ctx, hash)
-	if err != nil {
-		return txhash, err
-	}
-	tx.Signature = signature
-
-	execCtx, execCancel := context.WithTimeout(ctx, txm.cfg.TxTimeout())
-	defer execCancel()
-
-	// finally, transmit the invoke
-	res, err := account.AddInvokeTransaction(execCtx, tx)
-	if err != nil {
-		// TODO: handle initial broadcast errors - what kind of errors occur?
-		return txhash, fmt.Errorf("failed to invoke tx: %+w", err)
-	}
-	// handle nil pointer
-	if res == nil {
-		return txhash, errors.New("execute response and error are nil")
-	}
-
-	// update nonce if transaction is successful
-	txhash = res.TransactionHash.String()
-	err = errors.Join(
-		txm.nonce.IncrementNextSequence(publicKey, chainID, nonce),
-		txm.txStore.Save(accountAddress, nonce, txhash),
-	)
-	return txhash, err
+	return *friEstimate, nil
 }
 
 func (txm *starktxm) confirmLoop() {
@@ -281,32 +696,51 @@ func (txm *starktxm) confirmLoop() {
 				break
 			}
 
+			chainID, err := client.Provider.ChainID(ctx)
+			if err != nil {
+				txm.lggr.Errorw("failed to get chainID", "error", err)
+				break
+			}
+
 			hashes := txm.txStore.GetAllUnconfirmed()
 			for addr := range hashes {
 				for i := range hashes[addr] {
-					hash := hashes[addr][i]
-					f, err := starknetutils.HexToFelt(hash)
-					if err != nil {
-						txm.lggr.Errorw("invalid felt value", "hash", hash)
-						continue
-					}
-					response, err := client.Provider.GetTransactionStatus(ctx, f)
-					if err != nil {
-						txm.lggr.Errorw("failed to fetch transaction status", "hash", hash, "error", err)
-						continue
-					}
-
-					status := response.FinalityStatus
-
-					// any status other than received
-					if status == starknetrpc.TxnStatus_Accepted_On_L1 || status == starknetrpc.TxnStatus_Accepted_On_L2 || status == starknetrpc.TxnStatus_Rejected {
-						txm.lggr.Debugw(fmt.Sprintf("tx confirmed: %s", status), "hash", hash, "status", status)
-						if err := txm.txStore.Confirm(addr, hash); err != nil {
-							txm.lggr.Errorw("failed to confirm tx in TxStore", "hash", hash, "sender", addr, "error", err)
-						}
+					if txm.pollTx(ctx, client, addr, hashes[addr][i]) {
+						txm.checkConfirmation(ctx, client, chainID, addr, hashes[addr][i])
 					}
 				}
 			}
+			txm.checkFinality(ctx, client)
+			cutoff := time.Now().Add(-txm.cfg.TxStoreRetention())
+			txm.status.prune(cutoff)
+			// txs are tracked until they settle on L1 if that is the finality target
+			if _, err := txm.txStore.Prune(cutoff, txm.cfg.FinalityTarget() == starknetrpc.TxnStatus_Accepted_On_L1); err != nil {
+				txm.lggr.Errorw("failed to prune tx store", "error", err)
+			}
+			txm.status.expire(func(id string) bool {
+				_, err := txm.GetTransactionStatus(id)
+				return !errors.Is(err, ErrTxNotFound)
+			})
+
+			if timeout := txm.cfg.StuckTxTimeout(); timeout > 0 {
+				txm.resubmitStuck(ctx, time.Now().Add(-timeout))
+			}
+		case tx := <-txm.pushed:
+			// confirm right away, the poll schedule is kept
+			client, err := txm.client.Get()
+			if err != nil {
+				txm.lggr.Errorw("failed to load client", "error", err)
+				continue
+			}
+			chainID, err := client.Provider.ChainID(ctx)
+			if err != nil {
+				txm.lggr.Errorw("failed to get chainID", "error", err)
+				continue
+			}
+			if rec, err := txm.txStore.Get(tx.hash); err == nil && rec.Status == TxStatusUnconfirmed {
+				txm.checkConfirmation(ctx, client, chainID, tx.addr, tx.hash)
+			}
+			continue
 		case <-txm.stop:
 			txm.lggr.Debugw("confirmLoop: stopped")
 			return
@@ -316,11 +750,136 @@ func (txm *starktxm) confirmLoop() {
 	}
 }
 
+// resubmitStuck replaces txs that have not been confirmed since the cutoff with fee bumped attempts
+func (txm *starktxm) resubmitStuck(ctx context.Context, cutoff time.Time) {
+	if txm.cfg.DryRun() {
+		return
+	}
+	stuck, err := txm.txStore.GetStuck(cutoff)
+	if err != nil {
+		txm.lggr.Errorw("failed to fetch stuck txs", "error", err)
+		return
+	}
+	for _, rec := range stuck {
+		if rec.Type == starknetrpc.TransactionType_DeployAccount {
+			// only invokes are replaced, account deploys are left to the confirmer
+			continue
+		}
+		if rec.Attempt+1 >= MaxTxAttempts {
+			txm.lggr.Errorw("tx stuck: max attempts reached, not resubmitting", "hash", rec.Hash, "sender", rec.AccountAddress, "nonce", rec.Nonce, "attempts", rec.Attempt+1)
+			continue
+		}
+		hash, err := txm.resubmit(ctx, rec)
+		if errors.Is(err, starknet.ErrInvalidNonce) {
+			txm.dropIfNonceUsed(ctx, rec)
+			continue
+		}
+		if err != nil {
+			txm.lggr.Errorw("failed to resubmit stuck tx", "hash", rec.Hash, "sender", rec.AccountAddress, "nonce", rec.Nonce, "error", err)
+			continue
+		}
+		txm.lggr.Infow("resubmitted stuck tx", "txhash", hash, "replaces", rec.Hash, "nonce", rec.Nonce, "attempt", rec.Attempt+1)
+	}
+}
+
+// dropIfNonceUsed stops tracking a stuck tx if its nonce was consumed on chain by a tx that is not tracked,
+// e.g. one sent from the same account outside of the TXM
+func (txm *starktxm) dropIfNonceUsed(ctx context.Context, rec TxRecord) {
+	client, err := txm.client.Get()
+	if err != nil {
+		txm.lggr.Errorw("failed to load client", "error", err)
+		return
+	}
+	chainNonce, err := client.AccountNonce(ctx, rec.AccountAddress)
+	if err != nil {
+		txm.lggr.Errorw("failed to fetch account nonce", "account", rec.AccountAddress, "error", err)
+		return
+	}
+	if chainNonce.Cmp(rec.Nonce) <= 0 {
+		return
+	}
+	// let the confirmer check whether one of the attempts was included before giving up on them
+	for _, hash := range txm.txStore.Attempts(rec.AccountAddress, rec.Hash) {
+		f, err := starknetutils.HexToFelt(hash)
+		if err != nil {
+			continue
+		}
+		if _, err := client.Provider.GetTransactionStatus(ctx, f); err == nil {
+			return
+		}
+	}
+	txm.lggr.Warnw("dropping stuck tx: nonce was used by another tx", "hash", rec.Hash, "sender", rec.AccountAddress, "nonce", rec.Nonce, "accountNonce", chainNonce)
+	if err := txm.txStore.Drop(rec.AccountAddress, rec.Hash); err != nil {
+		txm.lggr.Errorw("failed to drop tx from TxStore", "hash", rec.Hash, "sender", rec.AccountAddress, "error", err)
+	} else {
+		txm.notifyStatus(rec.IDs...)
+	}
+	if err := txm.Resync(ctx, rec.AccountAddress); err != nil {
+		txm.lggr.Errorw("failed to resync nonce", "account", rec.AccountAddress, "error", err)
+	}
+}
+
+// nonceSyncLoop periodically compares the local nonce of each account against the chain
+func (txm *starktxm) nonceSyncLoop() {
+	defer txm.done.Done()
+
+	ctx, cancel := utils.ContextFromChan(txm.stop)
+	defer cancel()
+
+	txm.lggr.Debugw("nonceSyncLoop: started")
+	for {
+		select {
+		case <-time.After(utils.WithJitter(txm.cfg.NonceSyncInterval())):
+			txm.accountsLock.RLock()
+			accounts := maps.Values(txm.accounts)
+			txm.accountsLock.RUnlock()
+
+			for _, acc := range accounts {
+				if err := txm.Resync(ctx, acc.address); err != nil {
+					txm.lggr.Errorw("failed to resync nonce", "account", acc.address, "error", err)
+				}
+			}
+		case <-txm.stop:
+			txm.lggr.Debugw("nonceSyncLoop: stopped")
+			return
+		}
+	}
+}
+
+func (txm *starktxm) Resync(ctx context.Context, accountAddress *felt.Felt) error {
+	txm.accountsLock.RLock()
+	acc, exists := txm.accounts[accountAddress.String()]
+	txm.accountsLock.RUnlock()
+	if !exists {
+		return fmt.Errorf("account has not enqueued any txs: %s", accountAddress)
+	}
+
+	client, err := txm.client.Get()
+	if err != nil {
+		txm.client.Reset()
+		return fmt.Errorf("resync: failed to fetch client: %+w", err)
+	}
+	chainID, err := client.Provider.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chainID: %+w", err)
+	}
+
+	acc.sendLock.Lock()
+	defer acc.sendLock.Unlock()
+	_, _, err = txm.nonce.Sync(ctx, acc.publicKey, chainID)
+	return err
+}
+
 func (txm *starktxm) Close() error {
 	return txm.starter.StopOnce("starktxm", func() error {
+		// prevent new workers from being added to the waitgroup
+		txm.accountsLock.Lock()
+		txm.running = false
+		txm.accountsLock.Unlock()
+
 		close(txm.stop)
 		txm.done.Wait()
-		return nil
+		return txm.txStore.Close()
 	})
 }
 
@@ -333,41 +892,149 @@ func (txm *starktxm) Ready() error {
 }
 
 func (txm *starktxm) HealthReport() map[string]error {
-	return map[string]error{txm.Name(): txm.Healthy()}
+	report := map[string]error{txm.Name(): txm.Healthy()}
+	if txm.monitorBalances() {
+		report[txm.Name()+".BalanceMonitor"] = txm.balanceHealth()
+	}
+	report[txm.Name()+".Queue"] = txm.queueHealth()
+	report[txm.Name()+".UnconfirmedAge"] = txm.unconfirmedAgeHealth()
+	report[txm.Name()+".Nonce"] = txm.nonceHealth()
+	return report
 }
 
-func (txm *starktxm) Enqueue(accountAddress, publicKey *felt.Felt, tx starknetrpc.FunctionCall) error {
+func (txm *starktxm) Enqueue(accountAddress, publicKey *felt.Felt, tx starknetrpc.FunctionCall, opts TxOpts) (string, error) {
+	if !opts.Deadline.IsZero() && time.Now().After(opts.Deadline) {
+		return "", fmt.Errorf("enqueue: deadline %s already passed", opts.Deadline)
+	}
+
 	// validate key exists for sender
 	// use the embedded Loopp Keystore to do this; the spec and design
 	// encourage passing nil data to the loop.Keystore.Sign as way to test
 	// existence of a key
 	if _, err := txm.ks.Loopp().Sign(context.Background(), publicKey.String(), nil); err != nil {
-		return fmt.Errorf("enqueue: failed to sign: %+w", err)
+		return "", fmt.Errorf("enqueue: failed to sign: %+w", err)
 	}
 
 	client, err := txm.client.Get()
 	if err != nil {
 		txm.client.Reset()
-		return fmt.Errorf("broadcast: failed to fetch client: %+w", err)
+		return "", fmt.Errorf("broadcast: failed to fetch client: %+w", err)
 	}
 
 	chainID, err := client.Provider.ChainID(context.TODO())
 	if err != nil {
-		return fmt.Errorf("failed to get chainID: %+w", err)
+		return "", fmt.Errorf("failed to get chainID: %+w", err)
+	}
+
+	// refuse accounts that can't send V3 txs before deploying or registering them
+	if _, err := txm.accountClass(context.TODO(), client.Provider, accountAddress); err != nil {
+		return "", fmt.Errorf("enqueue: %w", err)
 	}
 
 	// register account for nonce manager
-	if err := txm.nonce.Register(context.TODO(), accountAddress, publicKey, chainID, client); err != nil {
-		return fmt.Errorf("failed to register nonce: %+w", err)
+	nonceClient := &pendingNonceClient{client, txm.txStore}
+	err = txm.nonce.Register(context.TODO(), accountAddress, publicKey, chainID, nonceClient)
+	if errors.Is(err, starknet.ErrContractNotDeployed) && txm.cfg.AccountClassHash() != nil {
+		// bootstrap the account, queued txs are held until the deploy is included
+		if err := txm.deployAccount(context.TODO(), client, chainID, accountAddress, publicKey); err != nil {
+			return "", fmt.Errorf("enqueue: %w", err)
+		}
+		err = txm.nonce.Register(context.TODO(), accountAddress, publicKey, chainID, nonceClient)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to register nonce: %+w", err)
+	}
+	if err := txm.gated(accountAddress); err != nil {
+		return "", fmt.Errorf("enqueue: %w", err)
 	}
 
-	select {
-	case txm.queue <- Tx{publicKey: publicKey, accountAddress: accountAddress, call: tx}: // TODO fix naming here
-	default:
-		return fmt.Errorf("failed to enqueue transaction: %+v", tx)
+	id := uuid.NewString()
+	txm.status.queued(id)
+	acc := txm.account(chainID, accountAddress, publicKey)
+	superseded, err := acc.queue.Push(Tx{id: id, publicKey: publicKey, accountAddress: accountAddress, call: tx, opts: opts}) // TODO fix naming here
+	txm.supersede(logger.With(txm.lggr, "account", accountAddress), superseded)
+	acc.observeQueueDepth()
+	if err != nil {
+		txm.status.remove(id)
+		return "", fmt.Errorf("failed to enqueue transaction: %+v: %w", tx, err)
 	}
+	txm.notifyStatus(id)
 
-	return nil
+	return id, nil
+}
+
+func (txm *starktxm) EstimateFee(ctx context.Context, accountAddress, publicKey *felt.Felt, calls []starknetrpc.FunctionCall) (*big.Int, error) {
+	client, err := txm.client.Get()
+	if err != nil {
+		txm.client.Reset()
+		return nil, fmt.Errorf("estimate fee: failed to fetch client: %+w", err)
+	}
+	account, err := txm.newAccount(ctx, client, accountAddress, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := client.Provider.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chainID: %+w", err)
+	}
+	// estimates fail for nonces ahead of the account, so the on-chain nonce is used even if txs are inflight
+	nonce, err := client.AccountNonce(ctx, accountAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %+w", err)
+	}
+
+	policy := NewFeePolicy(txm.cfg)
+	tx, err := txm.newInvoke(ctx, account, policy, nonce, calls)
+	if err != nil {
+		return nil, err
+	}
+	estimate, err := txm.estimateFee(ctx, chainID, account, tx)
+	if err != nil {
+		return nil, err
+	}
+	bounds, tip, err := policy.ResourceBounds(estimate)
+	if err != nil {
+		return nil, err
+	}
+	return maxFee(bounds, tip), nil
+}
+
+// account returns the account for the address, creating it and starting its worker if needed
+func (txm *starktxm) account(chainID string, address, publicKey *felt.Felt) *txAccount {
+	txm.accountsLock.Lock()
+	defer txm.accountsLock.Unlock()
+	acc, exists := txm.accounts[address.String()]
+	if !exists {
+		acc = newTxAccount(chainID, address, publicKey)
+		txm.accounts[address.String()] = acc
+		if txm.running {
+			txm.done.Add(1)
+			go txm.broadcastLoop(acc)
+		}
+	}
+	return acc
+}
+
+// pendingNonceClient skips past nonces used by unconfirmed txs in the TxStore, the on-chain nonce
+// does not include them when txs are restored after a restart, or while the account deploy is inflight
+type pendingNonceClient struct {
+	NonceManagerClient
+	txStore *ChainTxStore
+}
+
+func (c *pendingNonceClient) AccountNonce(ctx context.Context, accountAddress *felt.Felt) (*felt.Felt, error) {
+	n, err := c.NonceManagerClient.AccountNonce(ctx, accountAddress)
+	if errors.Is(err, starknet.ErrContractNotDeployed) && c.txStore.Deploying(accountAddress) {
+		next, _ := c.txStore.NextNonce(accountAddress)
+		return next, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if next, exists := c.txStore.NextNonce(accountAddress); exists && next.Cmp(n) > 0 {
+		return next, nil
+	}
+	return n, nil
 }
 
 func (txm *starktxm) InflightCount() (queue int, unconfirmed int) {
@@ -375,5 +1042,11 @@ func (txm *starktxm) InflightCount() (queue int, unconfirmed int) {
 	for _, count := range list {
 		unconfirmed += count
 	}
-	return len(txm.queue), unconfirmed
+
+	txm.accountsLock.RLock()
+	defer txm.accountsLock.RUnlock()
+	for _, acc := range txm.accounts {
+		queue += acc.queue.Len()
+	}
+	return queue, unconfirmed
 }
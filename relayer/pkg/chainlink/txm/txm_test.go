@@ -71,8 +71,29 @@ func TestIntegration_Txm(t *testing.T) {
 	cfg := mocks.NewConfig(t)
 	cfg.On("TxTimeout").Return(20 * time.Second)
 	cfg.On("ConfirmationPoll").Return(1 * time.Second)
-
-	txm, err := New(lggr, ksAdapter.Loopp(), cfg, getClient)
+	cfg.On("TxStorePath").Return("")
+	cfg.On("TxStoreRetention").Return(24 * time.Hour)
+	cfg.On("StuckTxTimeout").Return(time.Minute)
+	cfg.On("NonceSyncInterval").Return(time.Minute)
+	cfg.On("BatchWindow").Return(time.Duration(0))
+	cfg.On("L1GasAmountPercent").Return(uint32(0))
+	cfg.On("L1GasPricePercent").Return(uint32(0))
+	cfg.On("L2GasAmountPercent").Return(uint32(140))
+	cfg.On("L2GasPricePercent").Return(uint32(100))
+	cfg.On("L1GasMaxPrice").Return(uint64(0))
+	cfg.On("L2GasMaxPrice").Return(uint64(0))
+	cfg.On("MaxFee").Return(uint64(0))
+	cfg.On("TipStrategy").Return(string(TipNone))
+	cfg.On("Tip").Return(uint64(0))
+	cfg.On("NonceDAMode").Return(starknetrpc.DAModeL1)
+	cfg.On("FeeDAMode").Return(starknetrpc.DAModeL1)
+	cfg.On("SimulateTxs").Return(true)
+	cfg.On("DryRun").Return(false)
+	cfg.On("FinalityTarget").Return(starknetrpc.TxnStatus_Accepted_On_L2)
+	cfg.On("PaymasterAddress").Return(nil)
+	cfg.On("BalancePollInterval").Return(time.Duration(0))
+
+	txm, err := New(lggr, ksAdapter.Loopp(), cfg, getClient, nil)
 	require.NoError(t, err)
 
 	// ready fail if start not called
@@ -96,10 +117,11 @@ func TestIntegration_Txm(t *testing.T) {
 		selector := starknetutils.GetSelectorFromNameFelt("totalSupply")
 
 		for i := 0; i < n; i++ {
-			require.NoError(t, txm.Enqueue(accountAddress, publicKey, starknetrpc.FunctionCall{
+			_, err := txm.Enqueue(accountAddress, publicKey, starknetrpc.FunctionCall{
 				ContractAddress:    contractAddress, // send to ETH token contract
 				EntryPointSelector: selector,
-			}))
+			}, TxOpts{})
+			require.NoError(t, err)
 		}
 	}
 	var empty bool
@@ -1,24 +1,29 @@
 package txm
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
 	"golang.org/x/exp/maps"
 )
 
 // TxStore tracks broadcast & unconfirmed txs
 type TxStore struct {
 	lock         sync.RWMutex
-	nonceToHash  map[felt.Felt]string // map nonce to txhash
-	hashToNonce  map[string]felt.Felt // map hash to nonce
-	currentNonce felt.Felt            // minimum nonce
+	nonceToHash  map[felt.Felt][]string // map nonce to txhash of each attempt, latest attempt last
+	hashToNonce  map[string]felt.Felt   // map hash to nonce
+	currentNonce felt.Felt              // minimum nonce
 }
 
 func NewTxStore(current *felt.Felt) *TxStore {
 	return &TxStore{
-		nonceToHash:  map[felt.Felt]string{},
+		nonceToHash:  map[felt.Felt][]string{},
 		hashToNonce:  map[string]felt.Felt{},
 		currentNonce: *current,
 	}
@@ -33,14 +38,14 @@ func (s *TxStore) Save(nonce *felt.Felt, hash string) error {
 		return fmt.Errorf("nonce too low: %s < %s (lowest)", nonce, &s.currentNonce)
 	}
 	if h, exists := s.nonceToHash[*nonce]; exists {
-		return fmt.Errorf("nonce used: tried to use nonce (%s) for tx (%s), already used by (%s)", nonce, hash, h)
+		return fmt.Errorf("nonce used: tried to use nonce (%s) for tx (%s), already used by (%s)", nonce, hash, h[len(h)-1])
 	}
 	if n, exists := s.hashToNonce[hash]; exists {
 		return fmt.Errorf("hash used: tried to use tx (%s) for nonce (%s), already used nonce (%s)", hash, nonce, &n)
 	}
 
 	// store hash
-	s.nonceToHash[*nonce] = hash
+	s.nonceToHash[*nonce] = []string{hash}
 	s.hashToNonce[hash] = *nonce
 
 	// find next unused nonce
@@ -52,22 +57,83 @@ func (s *TxStore) Save(nonce *felt.Felt, hash string) error {
 	return nil
 }
 
+// SaveAttempt tracks a replacement tx for a nonce that is already in use
+func (s *TxStore) SaveAttempt(nonce *felt.Felt, hash string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, exists := s.nonceToHash[*nonce]; !exists {
+		return fmt.Errorf("nonce not used: tried to replace tx for nonce (%s) with tx (%s)", nonce, hash)
+	}
+	if n, exists := s.hashToNonce[hash]; exists {
+		return fmt.Errorf("hash used: tried to use tx (%s) for nonce (%s), already used nonce (%s)", hash, nonce, &n)
+	}
+
+	s.nonceToHash[*nonce] = append(s.nonceToHash[*nonce], hash)
+	s.hashToNonce[hash] = *nonce
+	return nil
+}
+
+// Confirm removes the nonce of the tx, along with every other attempt for the nonce
 func (s *TxStore) Confirm(hash string) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
 	if nonce, exists := s.hashToNonce[hash]; exists {
-		delete(s.hashToNonce, hash)
+		for _, h := range s.nonceToHash[nonce] {
+			delete(s.hashToNonce, h)
+		}
 		delete(s.nonceToHash, nonce)
 		return nil
 	}
 	return fmt.Errorf("tx hash does not exist - it may already be confirmed: %s", hash)
 }
 
+// Attempts returns all tx hashes that share a nonce with the given tx, including itself
+func (s *TxStore) Attempts(hash string) []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	nonce, exists := s.hashToNonce[hash]
+	if !exists {
+		return nil
+	}
+	return slices.Clone(s.nonceToHash[nonce])
+}
+
+// GetUnconfirmed returns the hashes of all unconfirmed attempts
 func (s *TxStore) GetUnconfirmed() []string {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
-	return maps.Values(s.nonceToHash)
+	return maps.Keys(s.hashToNonce)
+}
+
+// GetLatestAttempts returns the hash of the most recent attempt for each unconfirmed nonce
+func (s *TxStore) GetLatestAttempts() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	out := make([]string, 0, len(s.nonceToHash))
+	for _, hashes := range s.nonceToHash {
+		out = append(out, hashes[len(hashes)-1])
+	}
+	return out
+}
+
+// NextNonce returns the nonce after the highest unconfirmed nonce, false if nothing is inflight
+func (s *TxStore) NextNonce() (*felt.Felt, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var highest *felt.Felt
+	for n := range s.nonceToHash {
+		n := n
+		if highest == nil || n.Cmp(highest) > 0 {
+			highest = &n
+		}
+	}
+	if highest == nil {
+		return nil, false
+	}
+	return new(felt.Felt).Add(highest, new(felt.Felt).SetUint64(1)), true
 }
 
 func (s *TxStore) InflightCount() int {
@@ -77,36 +143,278 @@ func (s *TxStore) InflightCount() int {
 }
 
 type ChainTxStore struct {
-	store map[*felt.Felt]*TxStore
-	lock  sync.RWMutex
+	store   map[*felt.Felt]*TxStore
+	storage TxStorage
+	lock    sync.RWMutex
 }
 
+// NewChainTxStore creates a ChainTxStore that only tracks txs in memory
 func NewChainTxStore() *ChainTxStore {
+	return NewChainTxStoreWithStorage(NewMemoryTxStorage())
+}
+
+// NewChainTxStoreWithStorage creates a ChainTxStore that persists every tx attempt to the given storage
+func NewChainTxStoreWithStorage(storage TxStorage) *ChainTxStore {
 	return &ChainTxStore{
-		store: map[*felt.Felt]*TxStore{},
+		store:   map[*felt.Felt]*TxStore{},
+		storage: storage,
 	}
 }
 
-func (c *ChainTxStore) Save(from *felt.Felt, nonce *felt.Felt, hash string) error {
+// Load restores unconfirmed txs from the storage backend so they continue to be confirmed after a restart
+func (c *ChainTxStore) Load() (int, error) {
+	records, err := c.storage.Unconfirmed()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load unconfirmed txs: %w", err)
+	}
+	// save in nonce order so each account's TxStore starts from its lowest tracked nonce,
+	// replacements are restored after the first attempt of their nonce
+	sort.Slice(records, func(i, j int) bool {
+		if c := records[i].Nonce.Cmp(records[j].Nonce); c != 0 {
+			return c < 0
+		}
+		return records[i].Attempt < records[j].Attempt
+	})
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var errs []error
+	for _, rec := range records {
+		from, exists := c.key(rec.AccountAddress)
+		if !exists {
+			from = rec.AccountAddress
+			c.store[from] = NewTxStore(rec.Nonce)
+		}
+		save := c.store[from].Save
+		if rec.Attempt > 0 {
+			save = c.store[from].SaveAttempt
+		}
+		if err := save(rec.Nonce, rec.Hash); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(records), errors.Join(errs...)
+}
+
+// Save tracks a broadcast tx and persists it as [TxStatusUnconfirmed]
+func (c *ChainTxStore) Save(rec TxRecord) error {
 	// use write lock for methods that modify underlying data
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	if err := c.validate(from); err != nil {
+	from, exists := c.key(rec.AccountAddress)
+	if !exists {
 		// if does not exist, create a new store for the address
-		c.store[from] = NewTxStore(nonce)
+		from = rec.AccountAddress
+		c.store[from] = NewTxStore(rec.Nonce)
+	}
+	// the tx was already sent: persist it first so it survives a restart, and keep tracking it even if that fails
+	perr := c.put(rec)
+	if err := c.store[from].Save(rec.Nonce, rec.Hash); err != nil {
+		return errors.Join(perr, err)
+	}
+	return perr
+}
+
+// SaveAttempt tracks a replacement for an unconfirmed tx with the same nonce
+func (c *ChainTxStore) SaveAttempt(rec TxRecord) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	from, err := c.validate(rec.AccountAddress)
+	if err != nil {
+		return err
+	}
+	perr := c.put(rec)
+	if err := c.store[from].SaveAttempt(rec.Nonce, rec.Hash); err != nil {
+		return errors.Join(perr, err)
 	}
-	return c.store[from].Save(nonce, hash)
+	return perr
 }
 
-func (c *ChainTxStore) Confirm(from *felt.Felt, hash string) error {
+func (c *ChainTxStore) put(rec TxRecord) error {
+	now := time.Now()
+	rec.Status = TxStatusUnconfirmed
+	rec.CreatedAt = now
+	rec.UpdatedAt = now
+	if err := c.storage.Put(rec); err != nil {
+		return fmt.Errorf("failed to persist tx (%s): %w", rec.Hash, err)
+	}
+	return nil
+}
+
+// Confirm stops tracking the nonce of the tx and records the on-chain outcome, other attempts for the nonce are marked [TxStatusReplaced]
+func (c *ChainTxStore) Confirm(from *felt.Felt, hash string, outcome TxOutcome) error {
 	// use write lock for methods that modify underlying data
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	if err := c.validate(from); err != nil {
+	from, err := c.validate(from)
+	if err != nil {
+		return err
+	}
+	attempts := c.store[from].Attempts(hash)
+	if err := c.store[from].Confirm(hash); err != nil {
+		return err
+	}
+
+	errs := []error{c.update(hash, func(rec *TxRecord) {
+		rec.Status = outcome.Status()
+		rec.TxOutcome = outcome
+	})}
+	for _, h := range attempts {
+		if h != hash {
+			errs = append(errs, c.setStatus(h, TxStatusReplaced))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetFinality updates the finality status of an included tx, e.g. once it was accepted on L1
+func (c *ChainTxStore) SetFinality(hash string, finality starknetrpc.TxnStatus) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.update(hash, func(rec *TxRecord) { rec.FinalityStatus = finality })
+}
+
+// Drop stops tracking the nonce of the tx and marks every attempt for it as [TxStatusDropped]
+func (c *ChainTxStore) Drop(from *felt.Felt, hash string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	from, err := c.validate(from)
+	if err != nil {
 		return err
 	}
-	return c.store[from].Confirm(hash)
+	attempts := c.store[from].Attempts(hash)
+	if err := c.store[from].Confirm(hash); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, h := range attempts {
+		errs = append(errs, c.setStatus(h, TxStatusDropped))
+	}
+	return errors.Join(errs...)
+}
+
+// Attempts returns the hashes of all attempts sharing a nonce with the tx
+func (c *ChainTxStore) Attempts(from *felt.Felt, hash string) []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	from, exists := c.key(from)
+	if !exists {
+		return nil
+	}
+	return c.store[from].Attempts(hash)
+}
+
+// GetStuck returns the latest attempt of every unconfirmed nonce that was broadcast before the cutoff
+func (c *ChainTxStore) GetStuck(cutoff time.Time) ([]TxRecord, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var stuck []TxRecord
+	for _, s := range c.store {
+		for _, hash := range s.GetLatestAttempts() {
+			rec, err := c.storage.Get(hash)
+			if err != nil {
+				return nil, err
+			}
+			if rec.CreatedAt.Before(cutoff) {
+				stuck = append(stuck, rec)
+			}
+		}
+	}
+	return stuck, nil
+}
+
+// OldestUnconfirmed returns the earliest broadcast attempt that is still unconfirmed, false if all txs are confirmed
+func (c *ChainTxStore) OldestUnconfirmed() (TxRecord, bool, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var oldest TxRecord
+	var found bool
+	for _, s := range c.store {
+		for _, hash := range s.GetUnconfirmed() {
+			rec, err := c.storage.Get(hash)
+			if err != nil {
+				return TxRecord{}, false, err
+			}
+			if !found || rec.CreatedAt.Before(oldest.CreatedAt) {
+				oldest, found = rec, true
+			}
+		}
+	}
+	return oldest, found, nil
+}
+
+// Get returns the stored record of a tx attempt
+func (c *ChainTxStore) Get(hash string) (TxRecord, error) {
+	return c.storage.Get(hash)
+}
+
+// GetByID returns the stored records of every attempt that included the enqueued tx
+func (c *ChainTxStore) GetByID(id string) ([]TxRecord, error) {
+	return c.storage.GetByID(id)
+}
+
+// Deploying returns true if a DEPLOY_ACCOUNT tx of the account is unconfirmed
+func (c *ChainTxStore) Deploying(from *felt.Felt) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	from, exists := c.key(from)
+	if !exists {
+		return false
+	}
+	for _, hash := range c.store[from].GetUnconfirmed() {
+		if rec, err := c.storage.Get(hash); err == nil && rec.Type == starknetrpc.TransactionType_DeployAccount {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUnfinalized returns the records of included txs that have not been accepted on L1 yet
+func (c *ChainTxStore) GetUnfinalized() ([]TxRecord, error) {
+	return c.storage.Unfinalized()
+}
+
+// Prune removes terminal txs that were last updated before the cutoff from the storage.
+// Included txs that did not settle on L1 yet are kept if keepUnsettled is set.
+func (c *ChainTxStore) Prune(cutoff time.Time, keepUnsettled bool) (int, error) {
+	return c.storage.Prune(cutoff, keepUnsettled)
+}
+
+func (c *ChainTxStore) setStatus(hash string, status TxStatus) error {
+	return c.update(hash, func(rec *TxRecord) { rec.Status = status })
+}
+
+func (c *ChainTxStore) update(hash string, fn func(rec *TxRecord)) error {
+	rec, err := c.storage.Get(hash)
+	if err != nil {
+		return err
+	}
+	fn(&rec)
+	rec.UpdatedAt = time.Now()
+	if err := c.storage.Put(rec); err != nil {
+		return fmt.Errorf("failed to persist tx (%s): %w", hash, err)
+	}
+	return nil
+}
+
+// NextNonce returns the nonce following the highest tracked unconfirmed tx for the address
+func (c *ChainTxStore) NextNonce(from *felt.Felt) (*felt.Felt, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	from, exists := c.key(from)
+	if !exists {
+		return nil, false
+	}
+	return c.store[from].NextNonce()
 }
 
 func (c *ChainTxStore) GetAllInflightCount() map[*felt.Felt]int {
@@ -136,9 +444,27 @@ func (c *ChainTxStore) GetAllUnconfirmed() map[*felt.Felt][]string {
 	return list
 }
 
-func (c *ChainTxStore) validate(from *felt.Felt) error {
-	if _, exists := c.store[from]; !exists {
-		return fmt.Errorf("from address does not exist: %s", from)
+func (c *ChainTxStore) Close() error {
+	return c.storage.Close()
+}
+
+// key returns the map key used for the address, addresses are compared by value
+func (c *ChainTxStore) key(from *felt.Felt) (*felt.Felt, bool) {
+	if _, exists := c.store[from]; exists {
+		return from, true
 	}
-	return nil
+	for k := range c.store {
+		if k.Equal(from) {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+func (c *ChainTxStore) validate(from *felt.Felt) (*felt.Felt, error) {
+	k, exists := c.key(from)
+	if !exists {
+		return nil, fmt.Errorf("from address does not exist: %s", from)
+	}
+	return k, nil
 }
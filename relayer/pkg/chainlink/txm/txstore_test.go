@@ -3,8 +3,10 @@ package txm
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/stretchr/testify/assert"
@@ -125,6 +127,32 @@ func TestTxStore(t *testing.T) {
 	})
 }
 
+func TestTxStore_Attempts(t *testing.T) {
+	t.Parallel()
+
+	s := NewTxStore(new(felt.Felt).SetUint64(0))
+	nonce := new(felt.Felt).SetUint64(0)
+
+	// replacing requires an existing nonce
+	require.ErrorContains(t, s.SaveAttempt(nonce, "0xa1"), "nonce not used")
+
+	require.NoError(t, s.Save(nonce, "0xa0"))
+	require.NoError(t, s.SaveAttempt(nonce, "0xa1"))
+	require.ErrorContains(t, s.SaveAttempt(nonce, "0xa1"), "hash used")
+	require.NoError(t, s.Save(new(felt.Felt).SetUint64(1), "0xb0"))
+
+	assert.Equal(t, 2, s.InflightCount())
+	assert.ElementsMatch(t, []string{"0xa0", "0xa1", "0xb0"}, s.GetUnconfirmed())
+	assert.ElementsMatch(t, []string{"0xa1", "0xb0"}, s.GetLatestAttempts())
+	assert.Equal(t, []string{"0xa0", "0xa1"}, s.Attempts("0xa1"))
+
+	// confirming any attempt clears the nonce
+	require.NoError(t, s.Confirm("0xa0"))
+	assert.Equal(t, 1, s.InflightCount())
+	assert.Equal(t, []string{"0xb0"}, s.GetUnconfirmed())
+	require.ErrorContains(t, s.Confirm("0xa1"), "tx hash does not exist - it may already be confirmed")
+}
+
 func TestChainTxStore(t *testing.T) {
 	t.Parallel()
 
@@ -134,12 +162,12 @@ func TestChainTxStore(t *testing.T) {
 	felt1 := new(felt.Felt).SetUint64(1)
 
 	// automatically save the from address
-	require.NoError(t, c.Save(felt0, new(felt.Felt).SetUint64(0), "0x0"))
+	require.NoError(t, c.Save(TxRecord{AccountAddress: felt0, Nonce: new(felt.Felt).SetUint64(0), Hash: "0x0"}))
 
 	// reject saving for existing address and reused hash & nonce
 	// error messages are tested within TestTxStore
-	assert.Error(t, c.Save(felt0, new(felt.Felt).SetUint64(0), "0x1"))
-	assert.Error(t, c.Save(felt0, new(felt.Felt).SetUint64(1), "0x0"))
+	assert.Error(t, c.Save(TxRecord{AccountAddress: felt0, Nonce: new(felt.Felt).SetUint64(0), Hash: "0x1"}))
+	assert.Error(t, c.Save(TxRecord{AccountAddress: felt0, Nonce: new(felt.Felt).SetUint64(1), Hash: "0x0"}))
 
 	// inflight count
 	count, exists := c.GetAllInflightCount()[felt0]
@@ -156,9 +184,9 @@ func TestChainTxStore(t *testing.T) {
 	assert.Equal(t, []string{"0x0"}, hashes)
 
 	// confirm
-	assert.NoError(t, c.Confirm(felt0, "0x0"))
-	assert.ErrorContains(t, c.Confirm(felt1, "0x0"), "from address does not exist")
-	assert.Error(t, c.Confirm(felt0, "0x1"))
+	assert.NoError(t, c.Confirm(felt0, "0x0", TxOutcome{}))
+	assert.ErrorContains(t, c.Confirm(felt1, "0x0", TxOutcome{}), "from address does not exist")
+	assert.Error(t, c.Confirm(felt0, "0x1", TxOutcome{}))
 	list = c.GetAllUnconfirmed()
 	assert.Equal(t, 1, len(list))
 	assert.Equal(t, 0, len(list[felt0]))
@@ -166,3 +194,96 @@ func TestChainTxStore(t *testing.T) {
 	assert.True(t, exists)
 	assert.Equal(t, 0, count)
 }
+
+func TestChainTxStore_Load(t *testing.T) {
+	t.Parallel()
+
+	storage := NewMemoryTxStorage()
+	c := NewChainTxStoreWithStorage(storage)
+
+	// copy of the address to check lookups are by value
+	from := new(felt.Felt).SetUint64(1)
+	fromCopy := new(felt.Felt).SetUint64(1)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, c.Save(TxRecord{AccountAddress: from, Nonce: new(felt.Felt).SetUint64(uint64(i + 5)), Hash: fmt.Sprintf("0x%d", i)}))
+	}
+	require.NoError(t, c.Confirm(fromCopy, "0x0", TxOutcome{}))
+
+	rec, err := storage.Get("0x0")
+	require.NoError(t, err)
+	assert.Equal(t, TxStatusConfirmed, rec.Status)
+
+	// restore into a new store, as on restart
+	restored := NewChainTxStoreWithStorage(storage)
+	count, err := restored.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, restored.GetAllInflightCount()[from])
+
+	next, exists := restored.NextNonce(fromCopy)
+	require.True(t, exists)
+	assert.Equal(t, new(felt.Felt).SetUint64(8), next)
+	_, exists = restored.NextNonce(new(felt.Felt).SetUint64(2))
+	assert.False(t, exists)
+
+	require.NoError(t, restored.Confirm(fromCopy, "0x1", TxOutcome{}))
+	require.NoError(t, restored.Confirm(fromCopy, "0x2", TxOutcome{}))
+	unconfirmed, err := storage.Unconfirmed()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(unconfirmed))
+}
+
+func TestChainTxStore_Attempts(t *testing.T) {
+	t.Parallel()
+
+	storage := NewMemoryTxStorage()
+	c := NewChainTxStoreWithStorage(storage)
+	from := new(felt.Felt).SetUint64(1)
+	nonce := new(felt.Felt).SetUint64(0)
+
+	require.NoError(t, c.Save(TxRecord{AccountAddress: from, Nonce: nonce, Hash: "0xa0"}))
+	cutoff := time.Now()
+	require.NoError(t, c.Save(TxRecord{AccountAddress: from, Nonce: new(felt.Felt).SetUint64(1), Hash: "0xb0"}))
+
+	// only the tx broadcast before the cutoff is stuck
+	stuck, err := c.GetStuck(cutoff)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stuck))
+	assert.Equal(t, "0xa0", stuck[0].Hash)
+
+	require.NoError(t, c.SaveAttempt(TxRecord{AccountAddress: from, Nonce: nonce, Hash: "0xa1", Attempt: 1}))
+	stuck, err = c.GetStuck(cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(stuck))
+
+	// attempts are restored after a restart
+	restored := NewChainTxStoreWithStorage(storage)
+	count, err := restored.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	require.NoError(t, restored.Confirm(from, "0xa0", TxOutcome{}))
+	rec, err := storage.Get("0xa0")
+	require.NoError(t, err)
+	assert.Equal(t, TxStatusConfirmed, rec.Status)
+	rec, err = storage.Get("0xa1")
+	require.NoError(t, err)
+	assert.Equal(t, TxStatusReplaced, rec.Status)
+}
+
+func TestChainTxStore_SaveNotPersisted(t *testing.T) {
+	t.Parallel()
+
+	storage, err := NewFileTxStorage(filepath.Join(t.TempDir(), "txs.jsonl"))
+	require.NoError(t, err)
+	c := NewChainTxStoreWithStorage(storage)
+	from := new(felt.Felt).SetUint64(1)
+
+	// the journal can't be written anymore, the sent tx is still tracked in memory
+	require.NoError(t, storage.file.Close())
+	require.Error(t, c.Save(TxRecord{AccountAddress: from, Nonce: new(felt.Felt).SetUint64(0), Hash: "0xa", IDs: []string{"id"}}))
+	assert.Equal(t, 1, c.GetAllInflightCount()[from])
+	recs, err := c.GetByID("id")
+	require.NoError(t, err)
+	assert.Equal(t, TxStatusUnconfirmed, recs[0].Status)
+}
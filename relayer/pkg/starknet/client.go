@@ -2,13 +2,14 @@ package starknet
 
 import (
 	"context"
+	"math/big"
 	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/NethermindEth/juno/core/felt"
-	starknetaccount "github.com/NethermindEth/starknet.go/account"
 	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	starknetutils "github.com/NethermindEth/starknet.go/utils"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/smartcontractkit/chainlink-common/pkg/logger"
@@ -19,6 +20,7 @@ import (
 type Reader interface {
 	CallContract(context.Context, CallOps) ([]*felt.Felt, error)
 	LatestBlockHeight(context.Context) (uint64, error)
+	BalanceOf(ctx context.Context, token *felt.Felt, account *felt.Felt) (*big.Int, error)
 
 	// provider interface
 	BlockWithTxHashes(ctx context.Context, blockID starknetrpc.BlockID) (*starknetrpc.Block, error)
@@ -38,6 +40,7 @@ type ReaderWriter interface {
 }
 
 var _ ReaderWriter = (*Client)(nil)
+var _ Subscriber = (*Client)(nil)
 
 // var _ starknettypes.Provider = (*Client)(nil)
 
@@ -45,27 +48,40 @@ type Client struct {
 	Provider       starknetrpc.RpcProvider
 	lggr           logger.Logger
 	defaultTimeout time.Duration
+	subscriber     Subscriber // nil without a WebSocket URL
 }
 
-// pass nil or 0 to timeout to not use built in default timeout
-func NewClient(_chainID string, baseURL string, lggr logger.Logger, timeout *time.Duration) (*Client, error) {
-	// TODO: chainID now unused
+// pass nil or 0 to timeout to not use built in default timeout.
+// The node must serve the chain with the id and a supported RPC spec version, which is checked before the first request.
+// Requests fail until the node could be verified, an empty chainID skips the check.
+func NewClient(chainID string, baseURL string, lggr logger.Logger, timeout *time.Duration) (*Client, error) {
+	return NewClientWithRetries(chainID, baseURL, lggr, timeout, DefaultRetryConfig)
+}
+
+// NewClientWithRetries returns a client that retries requests with the policy of their method class,
+// the timeout is shared by all attempts of a request
+func NewClientWithRetries(chainID string, baseURL string, lggr logger.Logger, timeout *time.Duration, retryCfg RetryConfig) (*Client, error) {
 	c, err := ethrpc.DialContext(context.Background(), baseURL)
 	if err != nil {
 		return nil, err
 	}
 
-	client := &Client{
-		Provider: starknetrpc.NewProvider(c),
-		lggr:     lggr,
-	}
-
 	// make copy to preserve value
 	// defensive in case the timeout reference is ever garbage collected or removed
-	if timeout == nil {
-		client.defaultTimeout = 0
-	} else {
-		client.defaultTimeout = *timeout
+	var defaultTimeout time.Duration
+	if timeout != nil {
+		defaultTimeout = *timeout
+	}
+
+	breaker := newCircuitBreaker(retryCfg.BreakerThreshold, retryCfg.BreakerCooldown)
+	do := breakerDo(lggr, breaker, starknetrpc.NewProvider(c))
+	if chainID != "" {
+		do = verifyDo(logger.With(lggr, "starknet-url", baseURL), chainID, defaultTimeout, do)
+	}
+	client := &Client{
+		Provider:       &funcProvider{do: retryDo(lggr, retryCfg, do)},
+		lggr:           lggr,
+		defaultTimeout: defaultTimeout,
 	}
 
 	return client, nil
@@ -82,12 +98,30 @@ func (c *Client) CallContract(ctx context.Context, ops CallOps) (data []*felt.Fe
 
 	res, err := c.Call(ctx, tx, starknetrpc.WithBlockTag("pending"))
 	if err != nil {
-		return nil, errors.Wrap(err, "error in client.CallContract")
+		return nil, errors.Wrap(ClassifyError(err), "error in client.CallContract")
 	}
 
 	return res, nil
 }
 
+// BalanceOf returns the ERC20 token balance of the account
+func (c *Client) BalanceOf(ctx context.Context, token *felt.Felt, account *felt.Felt) (*big.Int, error) {
+	out, err := c.CallContract(ctx, CallOps{
+		ContractAddress: token,
+		Selector:        starknetutils.GetSelectorFromNameFelt("balanceOf"),
+		Calldata:        []*felt.Felt{account},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error in client.BalanceOf")
+	}
+	if len(out) != 2 {
+		return nil, errors.Errorf("error in client.BalanceOf: expected a u256, got %d felts", len(out))
+	}
+	// u256 is serialized as the low and high 128 bits
+	balance := new(big.Int).Lsh(out[1].BigInt(new(big.Int)), 128)
+	return balance.Add(balance, out[0].BigInt(new(big.Int))), nil
+}
+
 func (c *Client) LatestBlockHeight(ctx context.Context) (height uint64, err error) {
 	if c.defaultTimeout != 0 {
 		var cancel context.CancelFunc
@@ -97,7 +131,7 @@ func (c *Client) LatestBlockHeight(ctx context.Context) (height uint64, err erro
 
 	blockNum, err := c.Provider.BlockNumber(ctx)
 	if err != nil {
-		return height, errors.Wrap(err, "error in client.LatestBlockHeight")
+		return height, errors.Wrap(ClassifyError(err), "error in client.LatestBlockHeight")
 	}
 
 	return blockNum, nil
@@ -114,7 +148,7 @@ func (c *Client) BlockWithTxHashes(ctx context.Context, blockID starknetrpc.Bloc
 
 	out, err := c.Provider.BlockWithTxHashes(ctx, blockID)
 	if err != nil {
-		return out.(*starknetrpc.Block), errors.Wrap(err, "error in client.BlockWithTxHashes")
+		return out.(*starknetrpc.Block), errors.Wrap(ClassifyError(err), "error in client.BlockWithTxHashes")
 	}
 	return out.(*starknetrpc.Block), nil
 }
@@ -128,7 +162,7 @@ func (c *Client) Call(ctx context.Context, calls starknetrpc.FunctionCall, block
 
 	out, err := c.Provider.Call(ctx, calls, blockHashOrTag)
 	if err != nil {
-		return out, errors.Wrap(err, "error in client.Call")
+		return out, errors.Wrap(ClassifyError(err), "error in client.Call")
 	}
 	if out == nil {
 		return out, NilResultError("client.Call")
@@ -146,7 +180,7 @@ func (c *Client) TransactionByHash(ctx context.Context, hash *felt.Felt) (starkn
 
 	out, err := c.Provider.TransactionByHash(ctx, hash)
 	if err != nil {
-		return out, errors.Wrap(err, "error in client.TransactionByHash")
+		return out, errors.Wrap(ClassifyError(err), "error in client.TransactionByHash")
 	}
 	if out == nil {
 		return out, NilResultError("client.TransactionByHash")
@@ -164,7 +198,7 @@ func (c *Client) TransactionReceipt(ctx context.Context, hash *felt.Felt) (stark
 
 	out, err := c.Provider.TransactionReceipt(ctx, hash)
 	if err != nil {
-		return out, errors.Wrap(err, "error in client.TransactionReceipt")
+		return out, errors.Wrap(ClassifyError(err), "error in client.TransactionReceipt")
 	}
 	if out == nil {
 		return out, NilResultError("client.TransactionReceipt")
@@ -182,7 +216,7 @@ func (c *Client) Events(ctx context.Context, input starknetrpc.EventsInput) (*st
 
 	out, err := c.Provider.Events(ctx, input)
 	if err != nil {
-		return out, errors.Wrap(err, "error in client.Events")
+		return out, errors.Wrap(ClassifyError(err), "error in client.Events")
 	}
 	if out == nil {
 		return out, NilResultError("client.Events")
@@ -197,11 +231,38 @@ func (c *Client) AccountNonce(ctx context.Context, accountAddress *felt.Felt) (*
 		defer cancel()
 	}
 
-	sender := &felt.Zero // not actually used in account.Nonce()
-	cairoVersion := 2
-	account, err := starknetaccount.NewAccount(c.Provider, accountAddress, sender.String(), nil, cairoVersion)
+	// the nonce is read from the contract state, so it doesn't depend on the account class
+	nonce, err := c.Provider.Nonce(ctx, starknetrpc.BlockID{Tag: "latest"}, accountAddress)
 	if err != nil {
-		return nil, errors.Wrap(err, "error in client.AccountNonce")
+		return nil, errors.Wrap(ClassifyError(err), "error in client.AccountNonce")
+	}
+	return nonce, nil
+}
+
+// -- Subscriptions --
+
+// SubscriptionsEnabled returns true if the client can subscribe over a WebSocket connection
+func (c *Client) SubscriptionsEnabled() bool {
+	return c.subscriber != nil
+}
+
+func (c *Client) SubscribeNewHeads(ctx context.Context) (*Subscription[starknetrpc.BlockHeader], error) {
+	if c.subscriber == nil {
+		return nil, ErrSubscriptionsUnavailable
+	}
+	return c.subscriber.SubscribeNewHeads(ctx)
+}
+
+func (c *Client) SubscribeEvents(ctx context.Context, address *felt.Felt, keys [][]*felt.Felt) (*Subscription[starknetrpc.EmittedEvent], error) {
+	if c.subscriber == nil {
+		return nil, ErrSubscriptionsUnavailable
+	}
+	return c.subscriber.SubscribeEvents(ctx, address, keys)
+}
+
+func (c *Client) SubscribeTransactionStatus(ctx context.Context, hash *felt.Felt) (*Subscription[TxStatusUpdate], error) {
+	if c.subscriber == nil {
+		return nil, ErrSubscriptionsUnavailable
 	}
-	return account.Nonce(ctx, starknetrpc.BlockID{Tag: "latest"}, account.AccountAddress)
+	return c.subscriber.SubscribeTransactionStatus(ctx, hash)
 }
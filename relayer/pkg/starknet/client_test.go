@@ -41,6 +41,8 @@ func TestRPCClient(t *testing.T) {
 		case "starknet_chainId":
 			id := starknetutils.BigToHex(starknetutils.UTF8StrToBig(chainID))
 			out = []byte(fmt.Sprintf(`{"result": "%s"}`, id))
+		case "starknet_specVersion":
+			out = []byte(`{"result": "0.6.0"}`)
 		case "starknet_blockNumber":
 			out = []byte(`{"result": 1}`)
 		default:
@@ -0,0 +1,148 @@
+package starknet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// Classes of RPC errors, use [errors.Is] on errors returned by [ClassifyError] to match them
+var (
+	ErrInvalidNonce        = errors.New("invalid transaction nonce")
+	ErrInsufficientBalance = errors.New("insufficient account balance")
+	ErrValidationFailure   = errors.New("account validation failed")
+	ErrClassHashNotFound   = errors.New("class hash not found")
+	ErrContractNotDeployed = errors.New("contract not deployed")
+	ErrRateLimited         = errors.New("rate limited")
+	ErrNodeUnavailable     = errors.New("node unavailable")
+)
+
+var errorClasses = []error{
+	ErrInvalidNonce,
+	ErrInsufficientBalance,
+	ErrValidationFailure,
+	ErrClassHashNotFound,
+	ErrContractNotDeployed,
+	ErrRateLimited,
+	ErrNodeUnavailable,
+}
+
+// starknet RPC error codes, see https://github.com/starkware-libs/starknet-specs
+var codeClasses = map[int]error{
+	starknetrpc.ErrInvalidTransactionNonce.Code():    ErrInvalidNonce,
+	starknetrpc.ErrInsufficientAccountBalance.Code(): ErrInsufficientBalance,
+	starknetrpc.ErrValidationFailure.Code():          ErrValidationFailure,
+	starknetrpc.ErrClassHashNotFound.Code():          ErrClassHashNotFound,
+	starknetrpc.ErrContractNotFound.Code():           ErrContractNotDeployed,
+	-32005:                                           ErrRateLimited, // "limit exceeded" used by hosted node providers
+}
+
+// fallbackCodes are the codes of responses that carry their class in the message or data instead of the code:
+// devnet answers with internal errors, and execution or unexpected errors wrap the StarknetErrorCode of the sequencer
+var fallbackCodes = map[int]bool{
+	-32603:                                true, // internal error
+	starknetrpc.ErrTxnExec.Code():         true,
+	starknetrpc.ErrUnexpectedError.Code(): true,
+}
+
+// messageClasses match the messages of responses with one of the fallbackCodes, or of errors without a code
+var messageClasses = []struct {
+	substr string
+	class  error
+}{
+	{"invalid transaction nonce", ErrInvalidNonce}, // devnet
+	{"invalid_transaction_nonce", ErrInvalidNonce}, // sequencer
+	{"invalidnonce", ErrInvalidNonce},              // execution error data
+	{"insufficient_account_balance", ErrInsufficientBalance},
+	{"validate_failure", ErrValidationFailure},
+	{"undeclared_class", ErrClassHashNotFound},
+	{"is not deployed", ErrContractNotDeployed}, // devnet
+	{"uninitialized_contract", ErrContractNotDeployed},
+}
+
+// ClassifyError wraps err with its error class so it can be matched with [errors.Is].
+// Errors that are already classified, or that don't match a class, are returned unchanged.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, class := range errorClasses {
+		if errors.Is(err, class) {
+			return err
+		}
+	}
+	class := ErrorClass(err)
+	if class == nil {
+		return err
+	}
+	return fmt.Errorf("%w: %w", class, err)
+}
+
+// ErrorClass returns the class of the error as one of the Err* values of this package, nil if unknown
+func ErrorClass(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, class := range errorClasses {
+		if errors.Is(err, class) {
+			return class
+		}
+	}
+
+	// errors with a code are only matched by message if their code doesn't tell the class
+	coded := false
+	var rpcErr ethrpc.Error
+	if errors.As(err, &rpcErr) {
+		if class, ok := codeClasses[rpcErr.ErrorCode()]; ok {
+			return class
+		}
+		if !fallbackCodes[rpcErr.ErrorCode()] {
+			coded = true
+		}
+	}
+	var starknetErr *starknetrpc.RPCError
+	if errors.As(err, &starknetErr) {
+		if class, ok := codeClasses[starknetErr.Code()]; ok {
+			return class
+		}
+		if !fallbackCodes[starknetErr.Code()] {
+			coded = true
+		}
+	}
+
+	var httpErr ethrpc.HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return ErrRateLimited
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return ErrNodeUnavailable
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrNodeUnavailable
+	}
+
+	if coded {
+		return nil
+	}
+	msg := err.Error()
+	var dataErr ethrpc.DataError
+	if errors.As(err, &dataErr) {
+		msg += fmt.Sprintf(" %v", dataErr.ErrorData())
+	}
+	msg = strings.ToLower(msg)
+	for _, c := range messageClasses {
+		if strings.Contains(msg, c.substr) {
+			return c.class
+		}
+	}
+	return nil
+}
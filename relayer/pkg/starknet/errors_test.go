@@ -0,0 +1,100 @@
+package starknet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+)
+
+func TestErrorClass(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status int
+		body   string
+		class  error
+	}{
+		{"invalid nonce", http.StatusOK, `{"error": {"code": 52, "message": "Invalid transaction nonce"}}`, ErrInvalidNonce},
+		{"insufficient balance", http.StatusOK, `{"error": {"code": 54, "message": "Account balance is smaller than the transaction's max_fee"}}`, ErrInsufficientBalance},
+		{"validation failure", http.StatusOK, `{"error": {"code": 55, "message": "Account validation failed"}}`, ErrValidationFailure},
+		{"class hash not found", http.StatusOK, `{"error": {"code": 28, "message": "Class hash not found"}}`, ErrClassHashNotFound},
+		{"contract not found", http.StatusOK, `{"error": {"code": 20, "message": "Contract not found"}}`, ErrContractNotDeployed},
+		// devnet
+		{"not deployed message", http.StatusOK, `{"error": {"code": -32603, "message": "Requested contract address 0x1 is not deployed."}}`, ErrContractNotDeployed},
+		{"nonce message", http.StatusOK, `{"error": {"code": -32603, "message": "Invalid transaction nonce"}}`, ErrInvalidNonce},
+		{"nonce in data", http.StatusOK, `{"error": {"code": 41, "message": "Transaction execution error", "data": "InvalidNonce"}}`, ErrInvalidNonce},
+		// sequencer errors passed through by the node
+		{"sequencer nonce", http.StatusOK, `{"error": {"code": 63, "message": "An unexpected error occurred", "data": "StarknetErrorCode.INVALID_TRANSACTION_NONCE"}}`, ErrInvalidNonce},
+		{"sequencer balance", http.StatusOK, `{"error": {"code": 63, "message": "An unexpected error occurred", "data": "StarknetErrorCode.INSUFFICIENT_ACCOUNT_BALANCE"}}`, ErrInsufficientBalance},
+		{"sequencer validation", http.StatusOK, `{"error": {"code": 63, "message": "An unexpected error occurred", "data": "StarknetErrorCode.VALIDATE_FAILURE"}}`, ErrValidationFailure},
+		{"sequencer undeclared class", http.StatusOK, `{"error": {"code": 63, "message": "An unexpected error occurred", "data": "StarknetErrorCode.UNDECLARED_CLASS"}}`, ErrClassHashNotFound},
+		{"sequencer uninitialized contract", http.StatusOK, `{"error": {"code": 63, "message": "An unexpected error occurred", "data": "StarknetErrorCode.UNINITIALIZED_CONTRACT"}}`, ErrContractNotDeployed},
+		// the code decides the class of spec errors
+		{"coded message", http.StatusOK, `{"error": {"code": 40, "message": "Contract error: contract 0x1 is not deployed"}}`, nil},
+		{"rate limited", http.StatusTooManyRequests, `too many requests`, ErrRateLimited},
+		{"node unavailable", http.StatusServiceUnavailable, `unavailable`, ErrNodeUnavailable},
+		{"unknown", http.StatusOK, `{"error": {"code": 40, "message": "Contract error"}}`, nil},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, err := w.Write([]byte(tc.body))
+				require.NoError(t, err)
+			}))
+			defer server.Close()
+
+			// the node fails every request, so it can't be verified
+			client, err := NewClient("", server.URL, logger.Test(t), &timeout)
+			require.NoError(t, err)
+
+			_, err = client.AccountNonce(context.Background(), new(felt.Felt).SetUint64(1))
+			require.Error(t, err)
+			assert.Equal(t, tc.class, ErrorClass(err))
+			if tc.class != nil {
+				assert.ErrorIs(t, err, tc.class)
+			}
+		})
+	}
+
+	t.Run("node down", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.NotFoundHandler())
+		url := server.URL
+		server.Close()
+
+		client, err := NewClient("", url, logger.Test(t), &timeout)
+		require.NoError(t, err)
+		_, err = client.LatestBlockHeight(context.Background())
+		assert.ErrorIs(t, err, ErrNodeUnavailable)
+	})
+}
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, ClassifyError(nil))
+	assert.Nil(t, ErrorClass(nil))
+
+	unknown := errors.New("boom")
+	assert.Equal(t, unknown, ClassifyError(unknown))
+
+	// classifying is idempotent
+	err := ClassifyError(errors.New("Invalid transaction nonce"))
+	assert.ErrorIs(t, err, ErrInvalidNonce)
+	assert.Equal(t, err, ClassifyError(err))
+
+	// the class survives wrapping
+	wrapped := fmt.Errorf("failed to invoke tx: %w", err)
+	assert.Equal(t, ErrInvalidNonce, ErrorClass(wrapped))
+	assert.ErrorIs(t, ClassifyError(context.DeadlineExceeded), ErrNodeUnavailable)
+}
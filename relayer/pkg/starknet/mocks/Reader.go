@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	big "math/big"
+
 	context "context"
 
 	felt "github.com/NethermindEth/juno/core/felt"
@@ -44,6 +46,32 @@ func (_m *Reader) AccountNonce(_a0 context.Context, _a1 *felt.Felt) (*felt.Felt,
 	return r0, r1
 }
 
+// BalanceOf provides a mock function with given fields: ctx, token, account
+func (_m *Reader) BalanceOf(ctx context.Context, token *felt.Felt, account *felt.Felt) (*big.Int, error) {
+	ret := _m.Called(ctx, token, account)
+
+	var r0 *big.Int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *felt.Felt, *felt.Felt) (*big.Int, error)); ok {
+		return rf(ctx, token, account)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *felt.Felt, *felt.Felt) *big.Int); ok {
+		r0 = rf(ctx, token, account)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*big.Int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *felt.Felt, *felt.Felt) error); ok {
+		r1 = rf(ctx, token, account)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BlockWithTxHashes provides a mock function with given fields: ctx, blockID
 func (_m *Reader) BlockWithTxHashes(ctx context.Context, blockID rpc.BlockID) (*rpc.Block, error) {
 	ret := _m.Called(ctx, blockID)
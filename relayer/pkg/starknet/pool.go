@@ -0,0 +1,514 @@
+package starknet
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+)
+
+var (
+	// ErrNoNodes is returned by requests to a [Pool] without nodes
+	ErrNoNodes = errors.New("no nodes available")
+	// ErrNodeUnreachable is reported for nodes whose last probe or request failed
+	ErrNodeUnreachable = errors.New("node unreachable")
+	// ErrNodeBehind is reported for nodes that lag behind the highest node by more than the max sync lag
+	ErrNodeBehind = errors.New("node behind")
+	// ErrChainIDMismatch is reported for nodes that serve a different chain than the pool
+	ErrChainIDMismatch = errors.New("chain id mismatch")
+)
+
+// Node states reported by [NodeState.State]
+const (
+	NodeStateUnknown     = "Unknown" // not probed yet
+	NodeStateAlive       = "Alive"
+	NodeStateUnreachable = "Unreachable"
+	NodeStateOutOfSync   = "OutOfSync"
+	NodeStateWrongChain  = "WrongChain"
+	// NodeStateUnsupportedVersion is reported for nodes that serve an RPC spec version outside of the supported range
+	NodeStateUnsupportedVersion = "UnsupportedVersion"
+)
+
+// PoolNode is an RPC node of a [Pool]
+type PoolNode struct {
+	Name string
+	URL  string
+	// WSURL is the WebSocket endpoint of the node for subscriptions, optional. It can serve a newer
+	// RPC spec version than URL, as the WebSocket API is only part of the spec from 0.8.0 on.
+	WSURL string
+}
+
+// NodeState is the result of the last probe of a node, updated when requests to the node fail
+type NodeState struct {
+	Name    string
+	URL     string
+	Height  uint64
+	Latency time.Duration
+	// SpecVersion is the RPC spec version served by the node
+	SpecVersion string
+	// Primary is true for the node that requests are routed to first
+	Primary   bool
+	CheckedAt time.Time
+	// Err is the reason the node is unhealthy, nil if it is healthy or was not probed yet
+	Err error
+	// LastErr is the most recent error of the node, kept after it recovers
+	LastErr error
+}
+
+// State returns one of the NodeState* constants
+func (s NodeState) State() string {
+	switch {
+	case errors.Is(s.Err, ErrChainIDMismatch):
+		return NodeStateWrongChain
+	case errors.Is(s.Err, ErrUnsupportedSpecVersion):
+		return NodeStateUnsupportedVersion
+	case errors.Is(s.Err, ErrNodeBehind):
+		return NodeStateOutOfSync
+	case s.Err != nil:
+		return NodeStateUnreachable
+	case s.CheckedAt.IsZero():
+		return NodeStateUnknown
+	default:
+		return NodeStateAlive
+	}
+}
+
+type poolNode struct {
+	PoolNode
+	provider starknetrpc.RpcProvider
+	ws       *WSClient // nil without a WebSocket URL
+	breaker  *circuitBreaker
+	state    NodeState // guarded by the pool lock
+	verified bool      // guarded by the pool lock, true once the chain id and spec version of the node were checked
+}
+
+// Pool routes requests to the best healthy node and fails over to the next one on node errors.
+// The primary node is sticky: it serves all requests until it becomes unhealthy, so that
+// consecutive requests (e.g. reading a nonce and sending a tx) see the same chain state.
+type Pool struct {
+	lggr       logger.Logger
+	chainID    string
+	timeout    time.Duration
+	maxSyncLag uint64
+	retryCfg   RetryConfig
+
+	lock    sync.RWMutex
+	nodes   []*poolNode // in configured order
+	primary *poolNode
+}
+
+// NewPool dials the nodes, the first configured node is the primary until nodes are probed. Nodes that fail to dial
+// are redialed by the probes.
+// timeout limits each attempt of a request, 0 disables it. maxSyncLag is how many blocks a node
+// can be behind the highest node before it is unhealthy, 0 disables the check. Requests of the
+// pool client are retried with the policies of retryCfg, and each node has its own circuit breaker.
+func NewPool(chainID string, nodes []PoolNode, lggr logger.Logger, timeout time.Duration, maxSyncLag uint64, retryCfg RetryConfig) *Pool {
+	p := &Pool{
+		lggr:       logger.Named(lggr, "Pool"),
+		chainID:    chainID,
+		timeout:    timeout,
+		maxSyncLag: maxSyncLag,
+		retryCfg:   retryCfg,
+	}
+	for _, node := range nodes {
+		n := &poolNode{
+			PoolNode: node,
+			breaker:  newCircuitBreaker(retryCfg.BreakerThreshold, retryCfg.BreakerCooldown),
+			state:    NodeState{Name: node.Name, URL: node.URL},
+		}
+		provider, err := dialNode(context.Background(), node.URL)
+		if err != nil {
+			p.lggr.Warnw("failed to dial node, redialing on the next probe", "name", node.Name, "starknet-url", node.URL, "error", err)
+			n.state.Err = fmt.Errorf("%w: %w", ErrNodeUnreachable, err)
+			n.state.LastErr = n.state.Err
+		} else {
+			n.provider = provider
+		}
+		if node.WSURL != "" {
+			n.ws = NewWSClient(node.WSURL, logger.With(p.lggr, "name", node.Name))
+		}
+		p.nodes = append(p.nodes, n)
+	}
+	p.primary = p.best()
+	return p
+}
+
+// Client returns a client that sends every request through the pool, retrying them with the policy of their method class.
+// The client subscribes over the WebSocket connection of the best node that has one.
+func (p *Pool) Client() *Client {
+	client := &Client{Provider: &funcProvider{do: retryDo(p.lggr, p.retryCfg, p.do)}, lggr: p.lggr}
+	if slices.ContainsFunc(p.nodes, func(n *poolNode) bool { return n.ws != nil }) {
+		client.subscriber = poolSubscriber{p}
+	}
+	return client
+}
+
+// Close closes the WebSocket connections of the nodes, which ends all subscriptions
+func (p *Pool) Close() error {
+	var err error
+	for _, n := range p.nodes {
+		if n.ws != nil {
+			err = errors.Join(err, n.ws.Close())
+		}
+	}
+	return err
+}
+
+// States returns the state of every node in configured order
+func (p *Pool) States() []NodeState {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	states := make([]NodeState, len(p.nodes))
+	for i, n := range p.nodes {
+		states[i] = n.state
+		states[i].Primary = n == p.primary
+	}
+	return states
+}
+
+// Probe reads the chain id and height of all nodes concurrently, then ranks them.
+// Nodes that failed to dial are redialed first. The primary only changes if it became unhealthy.
+func (p *Pool) Probe(ctx context.Context) {
+	var wg sync.WaitGroup
+	states := make([]NodeState, len(p.nodes))
+	for i, n := range p.nodes {
+		wg.Add(1)
+		go func(i int, n *poolNode) {
+			defer wg.Done()
+			states[i] = p.probe(ctx, n)
+		}(i, n)
+	}
+	wg.Wait()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var highest uint64
+	for _, state := range states {
+		if state.Err == nil {
+			highest = max(highest, state.Height)
+		}
+	}
+	for i, n := range p.nodes {
+		state := states[i]
+		state.LastErr = n.state.LastErr
+		if state.Err == nil && p.maxSyncLag > 0 && highest-state.Height > p.maxSyncLag {
+			state.Err = fmt.Errorf("%w: at block %d, %d blocks behind the highest node", ErrNodeBehind, state.Height, highest-state.Height)
+		}
+		if state.Err != nil {
+			p.lggr.Warnw("node unhealthy", "name", n.Name, "starknet-url", n.URL, "error", state.Err)
+			state.LastErr = state.Err
+		}
+		switch {
+		case wrongNode(state.Err):
+			n.verified = false
+		case state.SpecVersion != "":
+			n.verified = true
+		}
+		n.state = state
+	}
+	if p.primary == nil || p.primary.state.Err != nil {
+		p.switchPrimary()
+	}
+}
+
+func (p *Pool) probe(ctx context.Context, n *poolNode) NodeState {
+	state := NodeState{Name: n.Name, URL: n.URL, CheckedAt: time.Now()}
+	ctx, cancel := verifyContext(ctx, p.timeout)
+	defer cancel()
+
+	provider, err := p.provider(ctx, n)
+	if err != nil {
+		state.Err = fmt.Errorf("%w: %w", ErrNodeUnreachable, err)
+		return state
+	}
+	state.SpecVersion, err = verifyNode(ctx, provider, p.chainID)
+	if wrongNode(err) {
+		state.Err = err
+		return state
+	}
+	if err != nil {
+		state.Err = fmt.Errorf("%w: %w", ErrNodeUnreachable, err)
+		return state
+	}
+	start := time.Now()
+	state.Height, err = provider.BlockNumber(ctx)
+	state.Latency = time.Since(start)
+	if err != nil {
+		state.Err = fmt.Errorf("%w: %w", ErrNodeUnreachable, err)
+	}
+	return state
+}
+
+// provider returns the provider of the node, dialing it if the node failed to dial before
+func (p *Pool) provider(ctx context.Context, n *poolNode) (starknetrpc.RpcProvider, error) {
+	p.lock.RLock()
+	provider := n.provider
+	p.lock.RUnlock()
+	if provider != nil {
+		return provider, nil
+	}
+
+	provider, err := dialNode(ctx, n.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial: %w", err)
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if n.provider == nil {
+		p.lggr.Infow("redialed node", "name", n.Name, "starknet-url", n.URL)
+		n.provider = provider
+	}
+	return n.provider, nil
+}
+
+func dialNode(ctx context.Context, url string) (starknetrpc.RpcProvider, error) {
+	c, err := ethrpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return starknetrpc.NewProvider(c), nil
+}
+
+// best returns the healthy node with the lowest latency, nodes that were not probed yet rank in configured order.
+// It must be called with the lock held.
+func (p *Pool) best() *poolNode {
+	var best *poolNode
+	for _, n := range p.nodes {
+		if n.provider == nil || n.state.Err != nil {
+			continue
+		}
+		if best == nil || n.state.Latency < best.state.Latency {
+			best = n
+		}
+	}
+	return best
+}
+
+// switchPrimary replaces the primary with the best healthy node, it must be called with the lock held
+func (p *Pool) switchPrimary() {
+	prev := p.primary
+	p.primary = p.best()
+	if p.primary == prev {
+		return
+	}
+	if p.primary == nil {
+		p.lggr.Errorw("no healthy nodes available")
+		return
+	}
+	p.lggr.Infow("switched primary node", "name", p.primary.Name, "starknet-url", p.primary.URL)
+}
+
+// ranked returns the nodes in the order requests try them: the primary, the other healthy nodes by latency,
+// then the unhealthy ones so that requests still go out if every node is marked unhealthy
+func (p *Pool) ranked() []*poolNode {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	ranked := make([]*poolNode, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		if n.provider != nil && !wrongNode(n.state.Err) {
+			ranked = append(ranked, n)
+		}
+	}
+	rank := func(n *poolNode) int {
+		switch {
+		case n == p.primary:
+			return 0
+		case n.state.Err == nil:
+			return 1
+		default:
+			return 2
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b *poolNode) int {
+		if ra, rb := rank(a), rank(b); ra != rb {
+			return ra - rb
+		}
+		return cmp.Compare(a.state.Latency, b.state.Latency)
+	})
+	return ranked
+}
+
+// markFailed marks the node unhealthy until its next successful probe and moves the primary off it
+func (p *Pool) markFailed(n *poolNode, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	n.state.Err = fmt.Errorf("%w: %w", ErrNodeUnreachable, err)
+	n.state.LastErr = n.state.Err
+	if n == p.primary {
+		p.switchPrimary()
+	}
+}
+
+// verify checks the chain id and spec version of a node before its first request, nodes that don't match are refused
+func (p *Pool) verify(ctx context.Context, n *poolNode) error {
+	p.lock.RLock()
+	verified := n.verified
+	p.lock.RUnlock()
+	if verified {
+		return nil
+	}
+
+	verifyCtx, cancel := verifyContext(ctx, p.timeout)
+	defer cancel()
+	version, err := verifyNode(verifyCtx, n.provider, p.chainID)
+	if err != nil && !wrongNode(err) {
+		p.markFailed(n, err)
+		return err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	n.state.SpecVersion = version
+	if err != nil {
+		p.lggr.Errorw("refusing node", "name", n.Name, "starknet-url", n.URL, "error", err)
+		n.state.Err, n.state.LastErr = err, err
+		if n == p.primary {
+			p.switchPrimary()
+		}
+		return err
+	}
+	n.verified = true
+	return nil
+}
+
+// markSucceeded clears the failure of a node that was marked unreachable, and makes it the primary if there is none.
+// Nodes that are behind or serve another chain stay unhealthy until the next probe.
+func (p *Pool) markSucceeded(n *poolNode) {
+	p.lock.RLock()
+	failed := errors.Is(n.state.Err, ErrNodeUnreachable) || p.primary == nil
+	p.lock.RUnlock()
+	if !failed {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if errors.Is(n.state.Err, ErrNodeUnreachable) {
+		n.state.Err = nil
+	}
+	if p.primary == nil {
+		p.switchPrimary()
+	}
+}
+
+func (p *Pool) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.timeout)
+}
+
+// do sends the request to the ranked nodes until one of them returns a result or an error that is not a node error.
+// Nodes with an open circuit are skipped. Writes only fail over if they could not be sent, a node that timed out
+// may still have received the tx and sending it to another node would duplicate it.
+func (p *Pool) do(ctx context.Context, class MethodClass, request func(context.Context, starknetrpc.RpcProvider) error) error {
+	err := ErrNoNodes
+	for _, n := range p.ranked() {
+		if verr := p.verify(ctx, n); verr != nil {
+			err = verr
+			continue
+		}
+		if berr := n.breaker.allow(); berr != nil {
+			err = berr
+			continue
+		}
+		attemptCtx, cancel := p.attemptContext(ctx)
+		err = request(attemptCtx, n.provider)
+		cancel()
+		// per-attempt timeouts count as node errors, ctx of the caller ending does not
+		if n.breaker.record(ctx, err) {
+			p.lggr.Warnw("circuit breaker opened", "name", n.Name, "starknet-url", n.URL, "cooldown", n.breaker.cooldown, "error", err)
+		}
+		if err == nil {
+			p.markSucceeded(n)
+			return nil
+		}
+		if !nodeError(err) || ctx.Err() != nil {
+			return err
+		}
+		p.markFailed(n, err)
+		if class == MethodClassWrite && !dialError(err) {
+			return err
+		}
+		p.lggr.Warnw("request to node failed, failing over", "name", n.Name, "starknet-url", n.URL, "error", err)
+	}
+	return err
+}
+
+// dialError returns true if the request failed to connect to the node, i.e. it was never sent
+func dialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// nodeError returns true if the error was caused by the node rather than the request, so that another node may succeed
+func nodeError(err error) bool {
+	switch ErrorClass(err) {
+	case ErrNodeUnavailable, ErrRateLimited:
+		return true
+	}
+	var httpErr ethrpc.HTTPError
+	return errors.As(err, &httpErr)
+}
+
+var _ Subscriber = poolSubscriber{}
+
+// poolSubscriber subscribes to the ranked nodes with a WebSocket URL until one of them accepts the subscription
+type poolSubscriber struct {
+	pool *Pool
+}
+
+func (s poolSubscriber) SubscribeNewHeads(ctx context.Context) (*Subscription[starknetrpc.BlockHeader], error) {
+	return poolSubscribe(ctx, s.pool, func(ws *WSClient) (*Subscription[starknetrpc.BlockHeader], error) {
+		return ws.SubscribeNewHeads(ctx)
+	})
+}
+
+func (s poolSubscriber) SubscribeEvents(ctx context.Context, address *felt.Felt, keys [][]*felt.Felt) (*Subscription[starknetrpc.EmittedEvent], error) {
+	return poolSubscribe(ctx, s.pool, func(ws *WSClient) (*Subscription[starknetrpc.EmittedEvent], error) {
+		return ws.SubscribeEvents(ctx, address, keys)
+	})
+}
+
+func (s poolSubscriber) SubscribeTransactionStatus(ctx context.Context, hash *felt.Felt) (*Subscription[TxStatusUpdate], error) {
+	return poolSubscribe(ctx, s.pool, func(ws *WSClient) (*Subscription[TxStatusUpdate], error) {
+		return ws.SubscribeTransactionStatus(ctx, hash)
+	})
+}
+
+// poolSubscribe fails over like requests do, but failed subscriptions don't mark nodes unhealthy
+// as the WebSocket endpoint is separate from the one serving requests
+func poolSubscribe[T any](ctx context.Context, p *Pool, subscribe func(*WSClient) (*Subscription[T], error)) (*Subscription[T], error) {
+	err := ErrSubscriptionsUnavailable
+	for _, n := range p.ranked() {
+		if n.ws == nil {
+			continue
+		}
+		if verr := p.verify(ctx, n); verr != nil {
+			err = verr
+			continue
+		}
+		sub, serr := subscribe(n.ws)
+		if serr == nil {
+			return sub, nil
+		}
+		if ctx.Err() != nil {
+			return nil, serr
+		}
+		p.lggr.Warnw("failed to subscribe to node, failing over", "name", n.Name, "starknet-ws-url", n.WSURL, "error", serr)
+		err = serr
+	}
+	return nil, err
+}
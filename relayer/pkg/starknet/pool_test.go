@@ -0,0 +1,279 @@
+package starknet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	starknetutils "github.com/NethermindEth/starknet.go/utils"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+)
+
+// testNode is a node of chainID at a fixed height that fails all requests while down
+type testNode struct {
+	server      *httptest.Server
+	chainID     string
+	specVersion string
+	height      uint64
+	delay       time.Duration
+	down        atomic.Bool
+	requests    atomic.Int32
+}
+
+func newTestNode(t *testing.T, chainID string, height uint64, delay time.Duration) *testNode {
+	n := &testNode{chainID: chainID, specVersion: MinSpecVersion, height: height, delay: delay}
+	n.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.requests.Add(1)
+		if n.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		time.Sleep(n.delay)
+		var req struct {
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		_, err := fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 1, "result": %s}`, n.result(req.Method))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(n.server.Close)
+	return n
+}
+
+func (n *testNode) result(method string) string {
+	switch method {
+	case "starknet_chainId":
+		return fmt.Sprintf("%q", starknetutils.BigToHex(starknetutils.UTF8StrToBig(n.chainID)))
+	case "starknet_specVersion":
+		return fmt.Sprintf("%q", n.specVersion)
+	default:
+		return fmt.Sprint(n.height)
+	}
+}
+
+// wsURL serves the node over WebSocket, connections are refused while the node is down
+func (n *testNode) wsURL(t *testing.T) string {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		for {
+			var req struct {
+				ID     json.RawMessage `json:"id"`
+				Method string          `json:"method"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			msg := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": %s}`, req.ID, n.result(req.Method))
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func primary(p *Pool) string {
+	for _, state := range p.States() {
+		if state.Primary {
+			return state.Name
+		}
+	}
+	return ""
+}
+
+func TestPool_Probe(t *testing.T) {
+	slow := newTestNode(t, chainID, 100, 50*time.Millisecond)
+	fast := newTestNode(t, chainID, 100, 0)
+	lagging := newTestNode(t, chainID, 80, 0)
+	other := newTestNode(t, "SN_OTHER", 100, 0)
+	down := newTestNode(t, chainID, 100, 0)
+	down.down.Store(true)
+
+	pool := NewPool(chainID, []PoolNode{
+		{Name: "slow", URL: slow.server.URL},
+		{Name: "fast", URL: fast.server.URL},
+		{Name: "lagging", URL: lagging.server.URL},
+		{Name: "other", URL: other.server.URL},
+		{Name: "down", URL: down.server.URL},
+	}, logger.Test(t), timeout, 10, RetryConfig{})
+	// the first configured node is the primary until nodes are probed
+	assert.Equal(t, "slow", primary(pool))
+	assert.Equal(t, NodeStateUnknown, pool.States()[0].State())
+
+	pool.Probe(context.Background())
+	states := pool.States()
+	require.Len(t, states, 5)
+	assert.NoError(t, states[0].Err)
+	assert.Equal(t, uint64(100), states[0].Height)
+	assert.NoError(t, states[1].Err)
+	assert.ErrorIs(t, states[2].Err, ErrNodeBehind)
+	assert.ErrorIs(t, states[3].Err, ErrChainIDMismatch)
+	assert.ErrorIs(t, states[4].Err, ErrNodeUnreachable)
+	for i, state := range []string{NodeStateAlive, NodeStateAlive, NodeStateOutOfSync, NodeStateWrongChain, NodeStateUnreachable} {
+		assert.Equal(t, state, states[i].State(), states[i].Name)
+	}
+	// the primary is sticky while it stays healthy
+	assert.Equal(t, "slow", primary(pool))
+
+	slow.down.Store(true)
+	pool.Probe(context.Background())
+	assert.Equal(t, "fast", primary(pool))
+
+	// the recovered node doesn't take over again
+	slow.down.Store(false)
+	pool.Probe(context.Background())
+	assert.NoError(t, pool.States()[0].Err)
+	assert.ErrorIs(t, pool.States()[0].LastErr, ErrNodeUnreachable)
+	assert.Equal(t, "fast", primary(pool))
+}
+
+func TestPool_Failover(t *testing.T) {
+	first := newTestNode(t, chainID, 100, 0)
+	second := newTestNode(t, chainID, 101, 0)
+	pool := NewPool(chainID, []PoolNode{
+		{Name: "first", URL: first.server.URL},
+		{Name: "second", URL: second.server.URL},
+	}, logger.Test(t), timeout, 0, RetryConfig{})
+	client := pool.Client()
+
+	height, err := client.LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), height)
+
+	// the primary goes down between requests, the next request fails over and moves the primary
+	first.down.Store(true)
+	height, err = client.LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(101), height)
+	assert.Equal(t, "second", primary(pool))
+	assert.ErrorIs(t, pool.States()[0].Err, ErrNodeUnreachable)
+
+	requests := first.requests.Load()
+	_, err = client.LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, requests, first.requests.Load(), "failed node is only tried after healthy nodes")
+
+	// all nodes down: every node is tried and the last error is returned
+	second.down.Store(true)
+	_, err = client.LatestBlockHeight(context.Background())
+	assert.ErrorIs(t, err, ErrNodeUnavailable)
+	assert.Empty(t, primary(pool))
+
+	// a failed node that serves a request again becomes the primary
+	first.down.Store(false)
+	_, err = client.LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "first", primary(pool))
+	assert.NoError(t, pool.States()[0].Err)
+
+	_, err = NewPool(chainID, nil, logger.Test(t), timeout, 0, RetryConfig{}).Client().LatestBlockHeight(context.Background())
+	assert.ErrorIs(t, err, ErrNoNodes)
+}
+
+func TestPool_WriteFailover(t *testing.T) {
+	t.Parallel()
+
+	first := newTestNode(t, chainID, 100, 0)
+	second := newTestNode(t, chainID, 101, 0)
+	pool := NewPool(chainID, []PoolNode{
+		{Name: "first", URL: first.server.URL},
+		{Name: "second", URL: second.server.URL},
+	}, logger.Test(t), timeout, 0, RetryConfig{})
+	client := pool.Client()
+	_, err := client.LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+	tx := starknetrpc.BroadcastInvokev3Txn{}
+
+	// the node may have received the tx, it is not sent again to another node
+	first.down.Store(true)
+	requests := second.requests.Load()
+	_, err = client.Provider.AddInvokeTransaction(context.Background(), tx)
+	assert.Equal(t, ErrNodeUnavailable, ErrorClass(err))
+	assert.Equal(t, requests, second.requests.Load())
+
+	// reads still fail over
+	height, err := client.LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(101), height)
+
+	// writes that could not connect to the primary are sent to the next node
+	first.down.Store(false)
+	require.Equal(t, "second", primary(pool))
+	second.server.Close()
+	requests = first.requests.Load()
+	_, err = client.Provider.AddInvokeTransaction(context.Background(), tx)
+	require.Error(t, err)
+	assert.Equal(t, requests+1, first.requests.Load())
+}
+
+func TestPool_Redial(t *testing.T) {
+	t.Parallel()
+
+	// WebSocket RPC URLs are connected when dialed
+	node := newTestNode(t, chainID, 100, 0)
+	node.down.Store(true)
+	pool := NewPool(chainID, []PoolNode{{Name: "node", URL: node.wsURL(t)}}, logger.Test(t), timeout, 0, RetryConfig{})
+	assert.ErrorIs(t, pool.States()[0].Err, ErrNodeUnreachable)
+	_, err := pool.Client().LatestBlockHeight(context.Background())
+	assert.ErrorIs(t, err, ErrNoNodes)
+
+	pool.Probe(context.Background())
+	assert.ErrorIs(t, pool.States()[0].Err, ErrNodeUnreachable)
+
+	// the node is redialed once it is back
+	node.down.Store(false)
+	pool.Probe(context.Background())
+	state := pool.States()[0]
+	require.NoError(t, state.Err)
+	assert.Equal(t, uint64(100), state.Height)
+	assert.Equal(t, "node", primary(pool))
+	height, err := pool.Client().LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), height)
+}
+
+func TestPool_BreakerCallerDeadline(t *testing.T) {
+	t.Parallel()
+
+	node := newTestNode(t, chainID, 100, 50*time.Millisecond)
+	pool := NewPool(chainID, []PoolNode{{Name: "node", URL: node.server.URL}}, logger.Test(t), timeout, 0, RetryConfig{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+	_, err := pool.Client().LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+
+	// short deadlines of the caller don't open the circuit of a healthy node
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		_, err := pool.Client().LatestBlockHeight(ctx)
+		cancel()
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	assert.False(t, pool.nodes[0].breaker.open())
+
+	// the attempt timeout of the pool does
+	slow := NewPool(chainID, []PoolNode{{Name: "node", URL: node.server.URL}}, logger.Test(t), 10*time.Millisecond, 0, RetryConfig{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+	slow.nodes[0].verified = true
+	for i := 0; i < 2; i++ {
+		_, err := slow.Client().LatestBlockHeight(context.Background())
+		require.Error(t, err)
+	}
+	assert.True(t, slow.nodes[0].breaker.open())
+}
@@ -0,0 +1,196 @@
+package starknet
+
+import (
+	"context"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+)
+
+// doFunc sends a request of the method class, e.g. through a [Pool] or with retries
+type doFunc func(ctx context.Context, class MethodClass, request func(context.Context, starknetrpc.RpcProvider) error) error
+
+// funcProvider implements the starknet provider interface by sending every request through a doFunc
+type funcProvider struct {
+	do doFunc
+}
+
+var _ starknetrpc.RpcProvider = (*funcProvider)(nil)
+
+// doCall sends a request that returns a result through the doFunc
+func doCall[T any](ctx context.Context, do doFunc, class MethodClass, request func(context.Context, starknetrpc.RpcProvider) (T, error)) (T, error) {
+	var out T
+	err := do(ctx, class, func(ctx context.Context, provider starknetrpc.RpcProvider) (err error) {
+		out, err = request(ctx, provider)
+		return err
+	})
+	return out, err
+}
+
+func (p *funcProvider) AddInvokeTransaction(ctx context.Context, invokeTxn starknetrpc.BroadcastInvokeTxnType) (*starknetrpc.AddInvokeTransactionResponse, error) {
+	return doCall(ctx, p.do, MethodClassWrite, func(ctx context.Context, provider starknetrpc.RpcProvider) (*starknetrpc.AddInvokeTransactionResponse, error) {
+		return provider.AddInvokeTransaction(ctx, invokeTxn)
+	})
+}
+
+func (p *funcProvider) AddDeclareTransaction(ctx context.Context, declareTransaction starknetrpc.BroadcastDeclareTxnType) (*starknetrpc.AddDeclareTransactionResponse, error) {
+	return doCall(ctx, p.do, MethodClassWrite, func(ctx context.Context, provider starknetrpc.RpcProvider) (*starknetrpc.AddDeclareTransactionResponse, error) {
+		return provider.AddDeclareTransaction(ctx, declareTransaction)
+	})
+}
+
+func (p *funcProvider) AddDeployAccountTransaction(ctx context.Context, deployAccountTransaction starknetrpc.BroadcastAddDeployTxnType) (*starknetrpc.AddDeployAccountTransactionResponse, error) {
+	return doCall(ctx, p.do, MethodClassWrite, func(ctx context.Context, provider starknetrpc.RpcProvider) (*starknetrpc.AddDeployAccountTransactionResponse, error) {
+		return provider.AddDeployAccountTransaction(ctx, deployAccountTransaction)
+	})
+}
+
+func (p *funcProvider) BlockHashAndNumber(ctx context.Context) (*starknetrpc.BlockHashAndNumberOutput, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (*starknetrpc.BlockHashAndNumberOutput, error) {
+		return provider.BlockHashAndNumber(ctx)
+	})
+}
+
+func (p *funcProvider) BlockNumber(ctx context.Context) (uint64, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (uint64, error) {
+		return provider.BlockNumber(ctx)
+	})
+}
+
+func (p *funcProvider) BlockTransactionCount(ctx context.Context, blockID starknetrpc.BlockID) (uint64, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (uint64, error) {
+		return provider.BlockTransactionCount(ctx, blockID)
+	})
+}
+
+func (p *funcProvider) BlockWithTxHashes(ctx context.Context, blockID starknetrpc.BlockID) (interface{}, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (interface{}, error) {
+		return provider.BlockWithTxHashes(ctx, blockID)
+	})
+}
+
+func (p *funcProvider) BlockWithTxs(ctx context.Context, blockID starknetrpc.BlockID) (interface{}, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (interface{}, error) {
+		return provider.BlockWithTxs(ctx, blockID)
+	})
+}
+
+func (p *funcProvider) Call(ctx context.Context, call starknetrpc.FunctionCall, block starknetrpc.BlockID) ([]*felt.Felt, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) ([]*felt.Felt, error) {
+		return provider.Call(ctx, call, block)
+	})
+}
+
+func (p *funcProvider) ChainID(ctx context.Context) (string, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (string, error) {
+		return provider.ChainID(ctx)
+	})
+}
+
+func (p *funcProvider) Class(ctx context.Context, blockID starknetrpc.BlockID, classHash *felt.Felt) (starknetrpc.ClassOutput, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (starknetrpc.ClassOutput, error) {
+		return provider.Class(ctx, blockID, classHash)
+	})
+}
+
+func (p *funcProvider) ClassAt(ctx context.Context, blockID starknetrpc.BlockID, contractAddress *felt.Felt) (starknetrpc.ClassOutput, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (starknetrpc.ClassOutput, error) {
+		return provider.ClassAt(ctx, blockID, contractAddress)
+	})
+}
+
+func (p *funcProvider) ClassHashAt(ctx context.Context, blockID starknetrpc.BlockID, contractAddress *felt.Felt) (*felt.Felt, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (*felt.Felt, error) {
+		return provider.ClassHashAt(ctx, blockID, contractAddress)
+	})
+}
+
+func (p *funcProvider) EstimateFee(ctx context.Context, requests []starknetrpc.BroadcastTxn, simulationFlags []starknetrpc.SimulationFlag, blockID starknetrpc.BlockID) ([]starknetrpc.FeeEstimate, error) {
+	return doCall(ctx, p.do, MethodClassSimulate, func(ctx context.Context, provider starknetrpc.RpcProvider) ([]starknetrpc.FeeEstimate, error) {
+		return provider.EstimateFee(ctx, requests, simulationFlags, blockID)
+	})
+}
+
+func (p *funcProvider) EstimateMessageFee(ctx context.Context, msg starknetrpc.MsgFromL1, blockID starknetrpc.BlockID) (*starknetrpc.FeeEstimate, error) {
+	return doCall(ctx, p.do, MethodClassSimulate, func(ctx context.Context, provider starknetrpc.RpcProvider) (*starknetrpc.FeeEstimate, error) {
+		return provider.EstimateMessageFee(ctx, msg, blockID)
+	})
+}
+
+func (p *funcProvider) Events(ctx context.Context, input starknetrpc.EventsInput) (*starknetrpc.EventChunk, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (*starknetrpc.EventChunk, error) {
+		return provider.Events(ctx, input)
+	})
+}
+
+func (p *funcProvider) GetTransactionStatus(ctx context.Context, transactionHash *felt.Felt) (*starknetrpc.TxnStatusResp, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (*starknetrpc.TxnStatusResp, error) {
+		return provider.GetTransactionStatus(ctx, transactionHash)
+	})
+}
+
+func (p *funcProvider) Nonce(ctx context.Context, blockID starknetrpc.BlockID, contractAddress *felt.Felt) (*felt.Felt, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (*felt.Felt, error) {
+		return provider.Nonce(ctx, blockID, contractAddress)
+	})
+}
+
+func (p *funcProvider) SimulateTransactions(ctx context.Context, blockID starknetrpc.BlockID, txns []starknetrpc.Transaction, simulationFlags []starknetrpc.SimulationFlag) ([]starknetrpc.SimulatedTransaction, error) {
+	return doCall(ctx, p.do, MethodClassSimulate, func(ctx context.Context, provider starknetrpc.RpcProvider) ([]starknetrpc.SimulatedTransaction, error) {
+		return provider.SimulateTransactions(ctx, blockID, txns, simulationFlags)
+	})
+}
+
+func (p *funcProvider) StateUpdate(ctx context.Context, blockID starknetrpc.BlockID) (*starknetrpc.StateUpdateOutput, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (*starknetrpc.StateUpdateOutput, error) {
+		return provider.StateUpdate(ctx, blockID)
+	})
+}
+
+func (p *funcProvider) StorageAt(ctx context.Context, contractAddress *felt.Felt, key string, blockID starknetrpc.BlockID) (string, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (string, error) {
+		return provider.StorageAt(ctx, contractAddress, key, blockID)
+	})
+}
+
+func (p *funcProvider) SpecVersion(ctx context.Context) (string, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (string, error) {
+		return provider.SpecVersion(ctx)
+	})
+}
+
+func (p *funcProvider) Syncing(ctx context.Context) (*starknetrpc.SyncStatus, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (*starknetrpc.SyncStatus, error) {
+		return provider.Syncing(ctx)
+	})
+}
+
+func (p *funcProvider) TraceBlockTransactions(ctx context.Context, blockID starknetrpc.BlockID) ([]starknetrpc.Trace, error) {
+	return doCall(ctx, p.do, MethodClassSimulate, func(ctx context.Context, provider starknetrpc.RpcProvider) ([]starknetrpc.Trace, error) {
+		return provider.TraceBlockTransactions(ctx, blockID)
+	})
+}
+
+func (p *funcProvider) TransactionByBlockIdAndIndex(ctx context.Context, blockID starknetrpc.BlockID, index uint64) (starknetrpc.Transaction, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (starknetrpc.Transaction, error) {
+		return provider.TransactionByBlockIdAndIndex(ctx, blockID, index)
+	})
+}
+
+func (p *funcProvider) TransactionByHash(ctx context.Context, hash *felt.Felt) (starknetrpc.Transaction, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (starknetrpc.Transaction, error) {
+		return provider.TransactionByHash(ctx, hash)
+	})
+}
+
+func (p *funcProvider) TransactionReceipt(ctx context.Context, transactionHash *felt.Felt) (starknetrpc.TransactionReceipt, error) {
+	return doCall(ctx, p.do, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) (starknetrpc.TransactionReceipt, error) {
+		return provider.TransactionReceipt(ctx, transactionHash)
+	})
+}
+
+func (p *funcProvider) TraceTransaction(ctx context.Context, transactionHash *felt.Felt) (starknetrpc.TxnTrace, error) {
+	return doCall(ctx, p.do, MethodClassSimulate, func(ctx context.Context, provider starknetrpc.RpcProvider) (starknetrpc.TxnTrace, error) {
+		return provider.TraceTransaction(ctx, transactionHash)
+	})
+}
@@ -0,0 +1,189 @@
+package starknet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+	"github.com/smartcontractkit/chainlink-common/pkg/utils"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit breaker of a node is open.
+// It is a [ErrNodeUnavailable] error so that pools fail over to another node.
+var ErrCircuitOpen = fmt.Errorf("%w: circuit breaker open", ErrNodeUnavailable)
+
+// MethodClass groups the provider methods that share a retry policy
+type MethodClass string
+
+const (
+	MethodClassRead     MethodClass = "read"     // queries of blocks, txs, events and contract state
+	MethodClassWrite    MethodClass = "write"    // tx submissions
+	MethodClassSimulate MethodClass = "simulate" // fee estimation, simulation and traces
+)
+
+// RetryPolicy retries requests that failed with a rate limit or node error, with exponential backoff and jitter
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt, 0 disables retries
+	MaxRetries     uint32
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// RetryConfig holds the retry policy of each method class and the circuit breaker settings of each node
+type RetryConfig struct {
+	Read     RetryPolicy
+	Write    RetryPolicy
+	Simulate RetryPolicy
+	// BreakerThreshold is the number of consecutive node errors that open the circuit of a node, 0 disables the breaker
+	BreakerThreshold uint32
+	// BreakerCooldown is how long the circuit stays open before a single trial request is let through
+	BreakerCooldown time.Duration
+}
+
+// DefaultRetryConfig retries reads, and simulations once. Writes are not retried as the TXM requeues them itself.
+var DefaultRetryConfig = RetryConfig{
+	Read:             RetryPolicy{MaxRetries: 3, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second},
+	Write:            RetryPolicy{MaxRetries: 0, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second},
+	Simulate:         RetryPolicy{MaxRetries: 1, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second},
+	BreakerThreshold: 5,
+	BreakerCooldown:  30 * time.Second,
+}
+
+func (c RetryConfig) policy(class MethodClass) RetryPolicy {
+	switch class {
+	case MethodClassWrite:
+		return c.Write
+	case MethodClassSimulate:
+		return c.Simulate
+	default:
+		return c.Read
+	}
+}
+
+// retryable returns true for errors that another attempt may not run into, requests to open circuits fail fast
+func retryable(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	switch ErrorClass(err) {
+	case ErrRateLimited, ErrNodeUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// retry sends the request until it succeeds, fails with an error that is not retryable, runs out of retries or ctx is done
+func retry(ctx context.Context, lggr logger.Logger, class MethodClass, policy RetryPolicy, request func(context.Context) error) error {
+	backoff := policy.InitialBackoff
+	for attempt := uint32(0); ; attempt++ {
+		err := request(ctx)
+		if err == nil || attempt >= policy.MaxRetries || !retryable(err) {
+			return err
+		}
+		wait := utils.WithJitter(backoff)
+		lggr.Debugw("request failed, retrying", "class", class, "attempt", attempt+1, "backoff", wait, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		backoff = min(2*backoff, policy.MaxBackoff)
+	}
+}
+
+// retryDo wraps a doFunc with the retry policies of the config
+func retryDo(lggr logger.Logger, cfg RetryConfig, do doFunc) doFunc {
+	return func(ctx context.Context, class MethodClass, request func(context.Context, starknetrpc.RpcProvider) error) error {
+		return retry(ctx, lggr, class, cfg.policy(class), func(ctx context.Context) error {
+			return do(ctx, class, request)
+		})
+	}
+}
+
+// circuitBreaker stops requests to a node after consecutive node errors. Once the cooldown passed
+// a single trial request is let through (half-open), which closes the circuit if it succeeds.
+type circuitBreaker struct {
+	threshold uint32
+	cooldown  time.Duration
+
+	lock      sync.Mutex
+	failures  uint32
+	openUntil time.Time
+	trial     bool // a trial request is in flight
+}
+
+func newCircuitBreaker(threshold uint32, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow returns [ErrCircuitOpen] if the request must not be sent
+func (b *circuitBreaker) allow() error {
+	if b.threshold == 0 {
+		return nil
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.trial || time.Now().Before(b.openUntil) {
+		return ErrCircuitOpen
+	}
+	b.trial = true
+	return nil
+}
+
+// record counts node errors of the request, other errors mean the node is serving requests and close the circuit.
+// Requests that failed because ctx of the caller ended say nothing about the node and are not counted.
+// It returns true if the request opened the circuit.
+func (b *circuitBreaker) record(ctx context.Context, err error) (opened bool) {
+	if b.threshold == 0 {
+		return false
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.trial = false
+	if err != nil && ctx.Err() != nil {
+		return false
+	}
+	if err == nil || !nodeError(err) {
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if b.failures < b.threshold {
+		return false
+	}
+	b.openUntil = time.Now().Add(b.cooldown)
+	return true
+}
+
+// open returns true while requests are rejected
+func (b *circuitBreaker) open() bool {
+	if b.threshold == 0 {
+		return false
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.failures >= b.threshold && (b.trial || time.Now().Before(b.openUntil))
+}
+
+// breakerDo sends requests to a single node through its circuit breaker
+func breakerDo(lggr logger.Logger, breaker *circuitBreaker, provider starknetrpc.RpcProvider) doFunc {
+	return func(ctx context.Context, _ MethodClass, request func(context.Context, starknetrpc.RpcProvider) error) error {
+		if err := breaker.allow(); err != nil {
+			return err
+		}
+		err := request(ctx, provider)
+		if breaker.record(ctx, err) {
+			lggr.Warnw("circuit breaker opened", "cooldown", breaker.cooldown, "error", err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,136 @@
+package starknet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+)
+
+func TestNewClientWithRetries(t *testing.T) {
+	t.Parallel()
+
+	// the node is rate limited for the first two requests
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, err := w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "result": 7}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	client, err := NewClientWithRetries("", server.URL, logger.Test(t), &timeout, RetryConfig{Read: policy})
+	require.NoError(t, err)
+
+	height, err := client.LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), height)
+	assert.Equal(t, int32(3), requests.Load())
+
+	// writes are not retried with the zero policy
+	requests.Store(0)
+	_, err = client.Provider.AddInvokeTransaction(context.Background(), nil)
+	assert.Equal(t, ErrRateLimited, ErrorClass(err))
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	for _, tc := range []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{name: "success", attempts: 1},
+		{name: "rate limited", err: ErrRateLimited, attempts: 4},
+		{name: "node unavailable", err: ErrNodeUnavailable, attempts: 4},
+		{name: "circuit open", err: ErrCircuitOpen, attempts: 1},
+		{name: "not retryable", err: ErrInvalidNonce, attempts: 1},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var attempts int
+			err := retry(context.Background(), logger.Test(t), MethodClassRead, policy, func(context.Context) error {
+				attempts++
+				return tc.err
+			})
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.attempts, attempts)
+		})
+	}
+
+	t.Run("context done", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		var attempts int
+		err := retry(ctx, logger.Test(t), MethodClassRead, RetryPolicy{MaxRetries: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour}, func(context.Context) error {
+			attempts++
+			cancel()
+			return ErrRateLimited
+		})
+		assert.ErrorIs(t, err, ErrRateLimited)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	breaker := newCircuitBreaker(2, 20*time.Millisecond)
+	require.NoError(t, breaker.allow())
+	assert.False(t, breaker.record(context.Background(), ErrNodeUnavailable))
+	// errors returned by a serving node reset the count
+	assert.False(t, breaker.record(context.Background(), errors.New("contract error")))
+	assert.False(t, breaker.record(context.Background(), ErrNodeUnavailable))
+	assert.True(t, breaker.record(context.Background(), ErrNodeUnavailable))
+
+	assert.True(t, breaker.open())
+	assert.ErrorIs(t, breaker.allow(), ErrCircuitOpen)
+	assert.ErrorIs(t, breaker.allow(), ErrNodeUnavailable)
+
+	// half-open after the cooldown: a single trial request is let through
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, breaker.allow())
+	assert.ErrorIs(t, breaker.allow(), ErrCircuitOpen)
+	// the failed trial reopens the circuit
+	assert.True(t, breaker.record(context.Background(), ErrNodeUnavailable))
+	assert.ErrorIs(t, breaker.allow(), ErrCircuitOpen)
+
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, breaker.allow())
+	assert.False(t, breaker.record(context.Background(), nil))
+	assert.False(t, breaker.open())
+	assert.NoError(t, breaker.allow())
+
+	// the caller giving up is not a node error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, breaker.allow())
+		assert.False(t, breaker.record(ctx, fmt.Errorf("%w: %w", ErrNodeUnavailable, ctx.Err())))
+	}
+	assert.False(t, breaker.open())
+
+	// disabled
+	disabled := newCircuitBreaker(0, 0)
+	for i := 0; i < 10; i++ {
+		disabled.record(context.Background(), ErrNodeUnavailable)
+	}
+	assert.NoError(t, disabled.allow())
+}
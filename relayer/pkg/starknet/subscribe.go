@@ -0,0 +1,530 @@
+package starknet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	"github.com/gorilla/websocket"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+	"github.com/smartcontractkit/chainlink-common/pkg/utils"
+)
+
+var (
+	// ErrSubscriptionsUnavailable is returned by subscriptions of clients without a WebSocket URL
+	ErrSubscriptionsUnavailable = errors.New("subscriptions unavailable: no WebSocket URL configured")
+	// ErrSubscriptionClosed ends subscriptions when their connection is closed
+	ErrSubscriptionClosed = errors.New("subscription closed")
+	// ErrSubscriptionLagging ends subscriptions whose consumer doesn't keep up with the pushed values
+	ErrSubscriptionLagging = errors.New("subscription lagging: consumer too slow")
+)
+
+const (
+	// subscriptionBuffer is the number of pushed values buffered per subscription
+	subscriptionBuffer = 64
+	// wsPingPeriod is how often connections are pinged, connections that don't answer within wsPongWait are closed
+	wsPingPeriod = 30 * time.Second
+	wsPongWait   = 2 * wsPingPeriod
+	// watchMinBackoff and watchMaxBackoff bound the backoff of [Watch] between subscription attempts
+	watchMinBackoff = time.Second
+	watchMaxBackoff = time.Minute
+)
+
+// TxStatusUpdate is pushed by transaction status subscriptions
+type TxStatusUpdate struct {
+	TransactionHash *felt.Felt                `json:"transaction_hash"`
+	Status          starknetrpc.TxnStatusResp `json:"status"`
+}
+
+// Subscriber subscribes to the Starknet WebSocket API. Subscriptions end when their connection is lost, see [Watch] to resubscribe.
+type Subscriber interface {
+	SubscribeNewHeads(ctx context.Context) (*Subscription[starknetrpc.BlockHeader], error)
+	// SubscribeEvents pushes the events emitted by the address, filtered by keys like [starknetrpc.EventFilter].
+	// A nil address matches all contracts.
+	SubscribeEvents(ctx context.Context, address *felt.Felt, keys [][]*felt.Felt) (*Subscription[starknetrpc.EmittedEvent], error)
+	SubscribeTransactionStatus(ctx context.Context, hash *felt.Felt) (*Subscription[TxStatusUpdate], error)
+}
+
+// SubscribeFunc opens a subscription, e.g. a [Subscriber] method with its arguments bound
+type SubscribeFunc[T any] func(context.Context) (*Subscription[T], error)
+
+// Subscription receives the values pushed by the node until it ends
+type Subscription[T any] struct {
+	lggr        logger.Logger
+	ch          chan T
+	err         chan error
+	unsubscribe func()
+
+	lock  sync.Mutex
+	ended bool
+}
+
+func newSubscription[T any](lggr logger.Logger) *Subscription[T] {
+	return &Subscription[T]{
+		lggr: lggr,
+		ch:   make(chan T, subscriptionBuffer),
+		err:  make(chan error, 1),
+	}
+}
+
+// C returns the channel of pushed values
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Err returns a channel that receives the error that ended the subscription, it is closed without an error by Unsubscribe
+func (s *Subscription[T]) Err() <-chan error {
+	return s.err
+}
+
+// Unsubscribe ends the subscription
+func (s *Subscription[T]) Unsubscribe() {
+	if s.unsubscribe != nil {
+		s.unsubscribe()
+	}
+	s.end(nil)
+}
+
+// deliver decodes and buffers a pushed value, the subscription ends if the buffer is full
+func (s *Subscription[T]) deliver(raw json.RawMessage) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		s.lggr.Errorw("failed to decode pushed value", "error", err)
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.ended {
+		return
+	}
+	select {
+	case s.ch <- v:
+	default:
+		s.endLocked(ErrSubscriptionLagging)
+	}
+}
+
+func (s *Subscription[T]) end(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.endLocked(err)
+}
+
+func (s *Subscription[T]) endLocked(err error) {
+	if s.ended {
+		return
+	}
+	s.ended = true
+	if err != nil {
+		s.err <- err
+	}
+	close(s.err)
+}
+
+// subscription is a [Subscription] of any type
+type subscription interface {
+	deliver(json.RawMessage)
+	end(error)
+}
+
+// Watch keeps a subscription open and calls handle with every pushed value until ctx is done.
+// After the subscription ends it resubscribes with exponential backoff, callers keep polling as a fallback in the meantime.
+// It returns right away if subscriptions are unavailable.
+func Watch[T any](ctx context.Context, lggr logger.Logger, subscribe SubscribeFunc[T], handle func(T)) {
+	backoff := watchMinBackoff
+	for {
+		sub, err := subscribe(ctx)
+		if errors.Is(err, ErrSubscriptionsUnavailable) {
+			return
+		}
+		if err == nil {
+			err = consume(ctx, sub, func(v T) {
+				backoff = watchMinBackoff
+				handle(v)
+			})
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		wait := utils.WithJitter(backoff)
+		lggr.Warnw("subscription failed, resubscribing", "backoff", wait, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		backoff = min(2*backoff, watchMaxBackoff)
+	}
+}
+
+// consume passes the pushed values to handle until the subscription ends or ctx is done
+func consume[T any](ctx context.Context, sub *Subscription[T], handle func(T)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			sub.Unsubscribe()
+			return ctx.Err()
+		case v := <-sub.C():
+			handle(v)
+		case err := <-sub.Err():
+			if err == nil {
+				err = ErrSubscriptionClosed
+			}
+			return err
+		}
+	}
+}
+
+var _ Subscriber = (*WSClient)(nil)
+
+// WSClient subscribes over a single WebSocket connection to a node, which is dialed on the first subscription
+// and redialed by the first subscription after it was lost
+type WSClient struct {
+	url  string
+	lggr logger.Logger
+
+	lock sync.Mutex
+	conn *wsConn
+}
+
+func NewWSClient(url string, lggr logger.Logger) *WSClient {
+	return &WSClient{url: url, lggr: logger.With(lggr, "starknet-ws-url", url)}
+}
+
+func (c *WSClient) SubscribeNewHeads(ctx context.Context) (*Subscription[starknetrpc.BlockHeader], error) {
+	return wsSubscribe[starknetrpc.BlockHeader](ctx, c, "starknet_subscribeNewHeads", struct{}{})
+}
+
+func (c *WSClient) SubscribeEvents(ctx context.Context, address *felt.Felt, keys [][]*felt.Felt) (*Subscription[starknetrpc.EmittedEvent], error) {
+	params := struct {
+		FromAddress *felt.Felt     `json:"from_address,omitempty"`
+		Keys        [][]*felt.Felt `json:"keys,omitempty"`
+	}{address, keys}
+	return wsSubscribe[starknetrpc.EmittedEvent](ctx, c, "starknet_subscribeEvents", params)
+}
+
+func (c *WSClient) SubscribeTransactionStatus(ctx context.Context, hash *felt.Felt) (*Subscription[TxStatusUpdate], error) {
+	params := struct {
+		TransactionHash *felt.Felt `json:"transaction_hash"`
+	}{hash}
+	return wsSubscribe[TxStatusUpdate](ctx, c, "starknet_subscribeTransactionStatus", params)
+}
+
+// Close closes the connection, which ends all subscriptions
+func (c *WSClient) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	c.conn.close(ErrSubscriptionClosed)
+	return nil
+}
+
+func (c *WSClient) connect(ctx context.Context) (*wsConn, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.conn != nil && !c.conn.isClosed() {
+		return c.conn, nil
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to dial %s: %w", ErrNodeUnavailable, c.url, err)
+	}
+	wc := newWSConn(conn, c.lggr)
+	if err := wc.verify(ctx); err != nil {
+		wc.close(err)
+		return nil, err
+	}
+	c.conn = wc
+	return c.conn, nil
+}
+
+func wsSubscribe[T any](ctx context.Context, c *WSClient, method string, params any) (*Subscription[T], error) {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sub := newSubscription[T](c.lggr)
+	result, err := conn.call(ctx, method, params, sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	id := subscriptionID(result)
+	sub.unsubscribe = func() { conn.unsubscribe(id) }
+	return sub, nil
+}
+
+type wsRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type wsMessage struct {
+	ID     *uint64         `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *wsError        `json:"error"`
+	Method string          `json:"method"`
+	Params struct {
+		SubscriptionID json.RawMessage `json:"subscription_id"`
+		Result         json.RawMessage `json:"result"`
+	} `json:"params"`
+}
+
+type wsError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *wsError) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("%d %s: %s", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// wsCall is a request waiting for its response, subscribe requests register sub under the returned subscription id
+type wsCall struct {
+	sub  subscription
+	done chan wsMessage
+}
+
+type wsConn struct {
+	conn *websocket.Conn
+	lggr logger.Logger
+
+	writeLock sync.Mutex
+
+	lock    sync.Mutex
+	nextID  uint64
+	pending map[uint64]*wsCall
+	subs    map[string]subscription
+	closed  chan struct{}
+	err     error // the reason the connection was closed
+}
+
+func newWSConn(conn *websocket.Conn, lggr logger.Logger) *wsConn {
+	c := &wsConn{
+		conn:    conn,
+		lggr:    lggr,
+		pending: map[uint64]*wsCall{},
+		subs:    map[string]subscription{},
+		closed:  make(chan struct{}),
+	}
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	go c.readLoop()
+	go c.pingLoop()
+	return c
+}
+
+// verify checks that the endpoint serves a spec version with subscriptions, see [WSMinSpecVersion]
+func (c *wsConn) verify(ctx context.Context) error {
+	result, err := c.call(ctx, "starknet_specVersion", []any{}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get spec version: %w", err)
+	}
+	var version string
+	if err := json.Unmarshal(result, &version); err != nil {
+		return fmt.Errorf("failed to decode spec version: %w", err)
+	}
+	if !supportedSpecVersion(version, WSMinSpecVersion, WSMaxSpecVersion) {
+		return fmt.Errorf("%w: WebSocket endpoint serves %s, supported are %s to %s (exclusive)", ErrUnsupportedSpecVersion, version, WSMinSpecVersion, WSMaxSpecVersion)
+	}
+	return nil
+}
+
+// call sends the request and returns the result, which is the subscription id for subscribe requests
+func (c *wsConn) call(ctx context.Context, method string, params any, sub subscription) (json.RawMessage, error) {
+	c.lock.Lock()
+	if c.err != nil {
+		c.lock.Unlock()
+		return nil, c.err
+	}
+	c.nextID++
+	id := c.nextID
+	call := &wsCall{sub: sub, done: make(chan wsMessage, 1)}
+	c.pending[id] = call
+	c.lock.Unlock()
+
+	if err := c.write(ctx, wsRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params}); err != nil {
+		c.close(err)
+		return nil, err
+	}
+
+	select {
+	case msg := <-call.done:
+		if msg.Error != nil {
+			return nil, msg.Error
+		}
+		return msg.Result, nil
+	case <-c.closed:
+		return nil, c.closedErr()
+	case <-ctx.Done():
+		c.lock.Lock()
+		delete(c.pending, id)
+		c.lock.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (c *wsConn) write(ctx context.Context, v any) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(wsPingPeriod)
+	}
+	if err := c.conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	return c.conn.WriteJSON(v)
+}
+
+// unsubscribe stops pushes of the subscription, the node is told in the background
+func (c *wsConn) unsubscribe(id string) {
+	c.lock.Lock()
+	_, ok := c.subs[id]
+	delete(c.subs, id)
+	c.lock.Unlock()
+	if !ok || c.isClosed() {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), wsPingPeriod)
+		defer cancel()
+		params := struct {
+			SubscriptionID json.RawMessage `json:"subscription_id"`
+		}{json.RawMessage(id)}
+		if _, err := c.call(ctx, "starknet_unsubscribe", params, nil); err != nil {
+			c.lggr.Debugw("failed to unsubscribe", "subscription", id, "error", err)
+		}
+	}()
+}
+
+func (c *wsConn) readLoop() {
+	if err := c.conn.SetReadDeadline(time.Now().Add(wsPongWait)); err != nil {
+		c.close(err)
+		return
+	}
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.close(fmt.Errorf("%w: %w", ErrSubscriptionClosed, err))
+			return
+		}
+		if err := c.conn.SetReadDeadline(time.Now().Add(wsPongWait)); err != nil {
+			c.close(err)
+			return
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.lggr.Errorw("failed to decode message", "error", err)
+			continue
+		}
+		if msg.ID != nil {
+			c.respond(*msg.ID, msg)
+			continue
+		}
+		c.lock.Lock()
+		sub, ok := c.subs[subscriptionID(msg.Params.SubscriptionID)]
+		c.lock.Unlock()
+		switch {
+		case !ok:
+			c.lggr.Debugw("dropping push of unknown subscription", "method", msg.Method, "subscription", string(msg.Params.SubscriptionID))
+		case msg.Method == "starknet_subscriptionReorg":
+			// reorged values are not retracted, consumers reread the chain state on the next push or poll
+			c.lggr.Debugw("chain reorganized", "subscription", string(msg.Params.SubscriptionID), "reorg", string(msg.Params.Result))
+		default:
+			sub.deliver(msg.Params.Result)
+		}
+	}
+}
+
+// respond hands the response to the waiting call, registering its subscription first so that no push is missed
+func (c *wsConn) respond(id uint64, msg wsMessage) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	call, ok := c.pending[id]
+	if !ok {
+		return
+	}
+	delete(c.pending, id)
+	if call.sub != nil && msg.Error == nil {
+		c.subs[subscriptionID(msg.Result)] = call.sub
+	}
+	call.done <- msg
+}
+
+func (c *wsConn) pingLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			c.writeLock.Lock()
+			err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsPingPeriod))
+			c.writeLock.Unlock()
+			if err != nil {
+				c.close(fmt.Errorf("%w: ping failed: %w", ErrSubscriptionClosed, err))
+				return
+			}
+		}
+	}
+}
+
+// close ends all subscriptions of the connection with the error
+func (c *wsConn) close(err error) {
+	c.lock.Lock()
+	if c.err != nil {
+		c.lock.Unlock()
+		return
+	}
+	c.err = err
+	subs := c.subs
+	c.subs = map[string]subscription{}
+	close(c.closed)
+	c.lock.Unlock()
+
+	c.lggr.Debugw("connection closed", "error", err)
+	_ = c.conn.Close()
+	for _, sub := range subs {
+		sub.end(err)
+	}
+}
+
+func (c *wsConn) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *wsConn) closedErr() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.err
+}
+
+// subscriptionID normalizes subscription ids, which are strings in the spec and integers in some nodes
+func subscriptionID(raw json.RawMessage) string {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		raw, _ = json.Marshal(id)
+	}
+	return string(raw)
+}
@@ -0,0 +1,259 @@
+package starknet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+)
+
+// testWSNode accepts subscriptions and pushes the values sent to push to all of them
+type testWSNode struct {
+	server      *httptest.Server
+	url         string
+	push        chan string
+	specVersion string
+
+	lock     sync.Mutex
+	requests []string // methods of all requests
+	conns    []*websocket.Conn
+}
+
+func newTestWSNode(t *testing.T) *testWSNode {
+	n := &testWSNode{push: make(chan string), specVersion: WSMinSpecVersion}
+	upgrader := websocket.Upgrader{}
+	n.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		n.lock.Lock()
+		n.conns = append(n.conns, conn)
+		n.lock.Unlock()
+
+		var writeLock sync.Mutex
+		write := func(msg string) error {
+			writeLock.Lock()
+			defer writeLock.Unlock()
+			return conn.WriteMessage(websocket.TextMessage, []byte(msg))
+		}
+		done := make(chan struct{})
+		defer close(done)
+		var subs []string
+		var subsLock sync.Mutex
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case result := <-n.push:
+					subsLock.Lock()
+					for _, id := range subs {
+						_ = write(fmt.Sprintf(`{"jsonrpc":"2.0","method":"starknet_subscriptionEvents","params":{"subscription_id":%s,"result":%s}}`, id, result))
+					}
+					subsLock.Unlock()
+				}
+			}
+		}()
+		for {
+			var req wsRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			n.lock.Lock()
+			n.requests = append(n.requests, req.Method)
+			n.lock.Unlock()
+			result := "true"
+			switch {
+			case req.Method == "starknet_specVersion":
+				result = fmt.Sprintf("%q", n.specVersion)
+			case req.Method == "starknet_unsubscribe":
+			case strings.HasPrefix(req.Method, "starknet_subscribe"):
+				// ids are integers in some nodes
+				result = fmt.Sprint(req.ID)
+				if req.ID%2 == 0 {
+					result = fmt.Sprintf(`"%d"`, req.ID)
+				}
+				subsLock.Lock()
+				subs = append(subs, result)
+				subsLock.Unlock()
+			default:
+				_ = write(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"Method not found"}}`, req.ID))
+				continue
+			}
+			if err := write(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, result)); err != nil {
+				return
+			}
+		}
+	}))
+	n.url = "ws" + strings.TrimPrefix(n.server.URL, "http")
+	t.Cleanup(n.server.Close)
+	return n
+}
+
+// drop closes all connections to the node
+func (n *testWSNode) drop() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, conn := range n.conns {
+		_ = conn.Close()
+	}
+	n.conns = nil
+}
+
+func (n *testWSNode) methods() []string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return append([]string(nil), n.requests...)
+}
+
+func receive[T any](t *testing.T, sub *Subscription[T]) T {
+	select {
+	case v := <-sub.C():
+		return v
+	case err := <-sub.Err():
+		require.FailNow(t, "subscription ended", err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for push")
+	}
+	var v T
+	return v
+}
+
+func TestWSClient_Subscribe(t *testing.T) {
+	node := newTestWSNode(t)
+	client := NewWSClient(node.url, logger.Test(t))
+	defer client.Close()
+	ctx := context.Background()
+
+	address := new(felt.Felt).SetUint64(1)
+	events, err := client.SubscribeEvents(ctx, address, [][]*felt.Felt{{new(felt.Felt).SetUint64(2)}})
+	require.NoError(t, err)
+	// the second subscription shares the connection and gets a string id
+	status, err := client.SubscribeTransactionStatus(ctx, new(felt.Felt).SetUint64(3))
+	require.NoError(t, err)
+
+	node.push <- `{"from_address":"0x1","keys":["0x2"],"data":["0x5"],"block_number":10,"transaction_hash":"0x3"}`
+	event := receive(t, events)
+	assert.Equal(t, address, event.FromAddress)
+	assert.Equal(t, uint64(10), event.BlockNumber)
+	// every subscription decodes its own type
+	assert.Equal(t, new(felt.Felt).SetUint64(3), receive(t, status).TransactionHash)
+
+	events.Unsubscribe()
+	err, ok := <-events.Err()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	require.Eventually(t, func() bool {
+		return strings.Contains(strings.Join(node.methods(), ","), "starknet_unsubscribe")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// errors of the node are returned
+	_, err = client.SubscribeNewHeads(ctx)
+	require.NoError(t, err)
+	_, err = wsSubscribe[starknetrpc.BlockHeader](ctx, client, "starknet_unknown", struct{}{})
+	assert.ErrorContains(t, err, "Method not found")
+
+	// connection loss ends all subscriptions, the next subscription redials
+	node.drop()
+	select {
+	case err := <-status.Err():
+		assert.ErrorIs(t, err, ErrSubscriptionClosed)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "subscription not ended")
+	}
+	_, err = client.SubscribeNewHeads(ctx)
+	require.NoError(t, err)
+
+	_, err = NewWSClient("ws://127.0.0.1:1", logger.Test(t)).SubscribeNewHeads(ctx)
+	assert.ErrorIs(t, err, ErrNodeUnavailable)
+
+	// endpoints without subscriptions are refused
+	old := newTestWSNode(t)
+	old.specVersion = MaxSpecVersion
+	_, err = NewWSClient(old.url, logger.Test(t)).SubscribeNewHeads(ctx)
+	assert.ErrorIs(t, err, ErrUnsupportedSpecVersion)
+}
+
+func TestSubscription_Lagging(t *testing.T) {
+	t.Parallel()
+
+	sub := newSubscription[int](logger.Test(t))
+	for i := 0; i <= subscriptionBuffer; i++ {
+		sub.deliver(json.RawMessage(fmt.Sprint(i)))
+	}
+	assert.ErrorIs(t, <-sub.Err(), ErrSubscriptionLagging)
+	assert.Len(t, sub.C(), subscriptionBuffer)
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	// unavailable subscriptions return right away
+	Watch(context.Background(), logger.Test(t), func(context.Context) (*Subscription[int], error) {
+		return nil, ErrSubscriptionsUnavailable
+	}, func(int) {})
+
+	// ended subscriptions are resubscribed
+	subs := make(chan *Subscription[int], 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pushed := make(chan int)
+	go Watch(ctx, logger.Test(t), func(context.Context) (*Subscription[int], error) {
+		sub := newSubscription[int](logger.Test(t))
+		subs <- sub
+		return sub, nil
+	}, func(v int) { pushed <- v })
+
+	first := <-subs
+	first.deliver(json.RawMessage("1"))
+	assert.Equal(t, 1, <-pushed)
+	first.end(ErrSubscriptionClosed)
+	second := <-subs
+	second.deliver(json.RawMessage("2"))
+	assert.Equal(t, 2, <-pushed)
+}
+
+func TestPool_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	// nodes without a WebSocket URL can't subscribe
+	plain := newTestNode(t, chainID, 100, 0)
+	client := NewPool(chainID, []PoolNode{{Name: "plain", URL: plain.server.URL}}, logger.Test(t), timeout, 0, RetryConfig{}).Client()
+	assert.False(t, client.SubscriptionsEnabled())
+	_, err := client.SubscribeNewHeads(context.Background())
+	assert.ErrorIs(t, err, ErrSubscriptionsUnavailable)
+
+	// the primary's WebSocket endpoint is down, the subscription fails over
+	first := newTestNode(t, chainID, 100, 0)
+	second := newTestNode(t, chainID, 100, 0)
+	ws := newTestWSNode(t)
+	pool := NewPool(chainID, []PoolNode{
+		{Name: "first", URL: first.server.URL, WSURL: "ws://127.0.0.1:1"},
+		{Name: "second", URL: second.server.URL, WSURL: ws.url},
+	}, logger.Test(t), timeout, 0, RetryConfig{})
+	defer pool.Close()
+	client = pool.Client()
+	require.True(t, client.SubscriptionsEnabled())
+
+	sub, err := client.SubscribeEvents(context.Background(), nil, nil)
+	require.NoError(t, err)
+	ws.push <- `{"from_address":"0x1","keys":[],"data":[],"transaction_hash":"0x3"}`
+	receive(t, sub)
+	// failed subscriptions don't mark nodes unhealthy
+	assert.Equal(t, "first", primary(pool))
+
+	require.NoError(t, pool.Close())
+	assert.ErrorIs(t, <-sub.Err(), ErrSubscriptionClosed)
+}
@@ -0,0 +1,179 @@
+package starknet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	starknetrpc "github.com/NethermindEth/starknet.go/rpc"
+	starknetutils "github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+)
+
+// ErrUnsupportedSpecVersion is returned for nodes that serve an RPC spec version outside of the supported range
+var ErrUnsupportedSpecVersion = errors.New("unsupported RPC spec version")
+
+// The range of RPC spec versions supported by the client, the max is exclusive
+const (
+	MinSpecVersion = "0.6.0"
+	MaxSpecVersion = "0.7.0"
+)
+
+// The range of RPC spec versions supported by the [WSClient], the max is exclusive.
+// Subscriptions were added in 0.8, nodes serve them on a separate endpoint from the one used by the client.
+const (
+	WSMinSpecVersion = "0.8.0"
+	WSMaxSpecVersion = "0.9.0"
+)
+
+// verifyTimeout bounds node verification when no request timeout is configured
+const verifyTimeout = 10 * time.Second
+
+// verifyContext bounds ctx by the timeout, or by verifyTimeout if it is 0
+func verifyContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		timeout = verifyTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// verifyNode checks that the node serves the chain with the id and a supported RPC spec version, and returns the spec version.
+// Mismatches return [ErrChainIDMismatch] or [ErrUnsupportedSpecVersion], other errors are returned as they are.
+func verifyNode(ctx context.Context, provider starknetrpc.RpcProvider, chainID string) (string, error) {
+	nodeChainID, err := provider.ChainID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get chain id: %w", err)
+	}
+	if want := normalizeChainID(chainID); nodeChainID != want {
+		return "", fmt.Errorf("%w: node is on %s, expected %s", ErrChainIDMismatch, nodeChainID, want)
+	}
+	version, err := provider.SpecVersion(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get spec version: %w", err)
+	}
+	if !supportedSpecVersion(version, MinSpecVersion, MaxSpecVersion) {
+		return version, fmt.Errorf("%w: node serves %s, supported are %s to %s (exclusive)", ErrUnsupportedSpecVersion, version, MinSpecVersion, MaxSpecVersion)
+	}
+	return version, nil
+}
+
+// verifyDo verifies the node with the first request instead of when the client is created, so that a node
+// that is unreachable at startup only fails requests until it is back. Nodes that serve another chain or an
+// unsupported spec version fail every request. Concurrent requests share a single verification and fail with
+// its error, requests are not held back once the node is verified.
+func verifyDo(lggr logger.Logger, chainID string, timeout time.Duration, do doFunc) doFunc {
+	type verification struct {
+		done chan struct{}
+		err  error
+	}
+	var (
+		lock     sync.Mutex
+		verified bool
+		refused  error
+		inflight *verification
+	)
+	verify := func(ctx context.Context) error {
+		lock.Lock()
+		if verified || refused != nil {
+			defer lock.Unlock()
+			return refused
+		}
+		if v := inflight; v != nil {
+			lock.Unlock()
+			select {
+			case <-v.done:
+				return v.err
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		v := &verification{done: make(chan struct{})}
+		inflight = v
+		lock.Unlock()
+
+		verifyCtx, cancel := verifyContext(ctx, timeout)
+		err := do(verifyCtx, MethodClassRead, func(ctx context.Context, provider starknetrpc.RpcProvider) error {
+			_, err := verifyNode(ctx, provider, chainID)
+			return err
+		})
+		cancel()
+
+		lock.Lock()
+		defer lock.Unlock()
+		switch {
+		case wrongNode(err):
+			lggr.Errorw("refusing node", "error", err)
+			refused = err
+		case err != nil:
+			err = fmt.Errorf("failed to verify node: %w", err)
+		default:
+			verified = true
+		}
+		inflight = nil
+		v.err = err
+		close(v.done)
+		return err
+	}
+	return func(ctx context.Context, class MethodClass, request func(context.Context, starknetrpc.RpcProvider) error) error {
+		if err := verify(ctx); err != nil {
+			return err
+		}
+		return do(ctx, class, request)
+	}
+}
+
+// wrongNode returns true for verification errors, requests must never be sent to these nodes
+func wrongNode(err error) bool {
+	return errors.Is(err, ErrChainIDMismatch) || errors.Is(err, ErrUnsupportedSpecVersion)
+}
+
+// normalizeChainID decodes hex encoded chain ids into the short string returned by nodes
+func normalizeChainID(chainID string) string {
+	if strings.HasPrefix(chainID, "0x") {
+		return starknetutils.HexToShortStr(chainID)
+	}
+	return chainID
+}
+
+// supportedSpecVersion returns true if from <= version < to
+func supportedSpecVersion(version string, from string, to string) bool {
+	v, err := parseSpecVersion(version)
+	if err != nil {
+		return false
+	}
+	minVersion, _ := parseSpecVersion(from)
+	maxVersion, _ := parseSpecVersion(to)
+	return compareSpecVersions(v, minVersion) >= 0 && compareSpecVersions(v, maxVersion) < 0
+}
+
+// parseSpecVersion parses a major.minor.patch version, the patch is optional and pre-release suffixes are ignored
+func parseSpecVersion(version string) ([3]int, error) {
+	var v [3]int
+	version, _, _ = strings.Cut(strings.TrimPrefix(version, "v"), "-")
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return v, fmt.Errorf("invalid spec version %q", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return v, fmt.Errorf("invalid spec version %q: %w", version, err)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+func compareSpecVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+package starknet
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-common/pkg/logger"
+)
+
+func TestSupportedSpecVersion(t *testing.T) {
+	t.Parallel()
+
+	for version, supported := range map[string]bool{
+		"0.6.0":        true,
+		"0.6.1":        true,
+		"v0.6.0":       true,
+		"0.6.0-rc5":    true,
+		"0.6":          true,
+		"0.5.1":        false,
+		"0.7.0":        false,
+		"0.7.0-rc0":    false,
+		"1.0.0":        false,
+		"":             false,
+		"not-a-semver": false,
+	} {
+		assert.Equal(t, supported, supportedSpecVersion(version, MinSpecVersion, MaxSpecVersion), version)
+	}
+	assert.True(t, supportedSpecVersion("0.8.0", WSMinSpecVersion, WSMaxSpecVersion))
+	assert.False(t, supportedSpecVersion("0.7.0", WSMinSpecVersion, WSMaxSpecVersion))
+}
+
+func TestNewClient_Verify(t *testing.T) {
+	t.Parallel()
+
+	node := newTestNode(t, chainID, 1, 0)
+	client, err := NewClient(chainID, node.server.URL, logger.Test(t), &timeout)
+	require.NoError(t, err)
+	_, err = client.LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+
+	// hex encoded chain ids are decoded
+	client, err = NewClient("0x534e5f474f45524c49", node.server.URL, logger.Test(t), &timeout)
+	require.NoError(t, err)
+	_, err = client.LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+
+	// wrong nodes fail every request
+	client, err = NewClient("SN_MAIN", node.server.URL, logger.Test(t), &timeout)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = client.LatestBlockHeight(context.Background())
+		assert.ErrorIs(t, err, ErrChainIDMismatch)
+	}
+
+	old := newTestNode(t, chainID, 1, 0)
+	old.specVersion = "0.5.1"
+	client, err = NewClient(chainID, old.server.URL, logger.Test(t), &timeout)
+	require.NoError(t, err)
+	_, err = client.LatestBlockHeight(context.Background())
+	assert.ErrorIs(t, err, ErrUnsupportedSpecVersion)
+
+	// nodes that are down at startup are verified once they are back
+	down := newTestNode(t, chainID, 1, 0)
+	down.down.Store(true)
+	client, err = NewClientWithRetries(chainID, down.server.URL, logger.Test(t), nil, RetryConfig{})
+	require.NoError(t, err)
+	_, err = client.LatestBlockHeight(context.Background())
+	assert.Error(t, err)
+	down.down.Store(false)
+	_, err = client.LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+}
+
+func TestNewClient_VerifyConcurrent(t *testing.T) {
+	t.Parallel()
+
+	// the node is slower than the verification timeout
+	slow := newTestNode(t, chainID, 1, 200*time.Millisecond)
+	verifyTimeout := 50 * time.Millisecond
+	client, err := NewClientWithRetries(chainID, slow.server.URL, logger.Test(t), &verifyTimeout, RetryConfig{})
+	require.NoError(t, err)
+
+	// concurrent requests wait for a single verification instead of verifying one after another
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Provider.BlockNumber(context.Background())
+			assert.ErrorContains(t, err, "failed to verify node")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), slow.requests.Load())
+}
+
+func TestPool_Verify(t *testing.T) {
+	t.Parallel()
+
+	old := newTestNode(t, chainID, 100, 0)
+	old.specVersion = "0.5.1"
+	good := newTestNode(t, chainID, 100, 0)
+	pool := NewPool(chainID, []PoolNode{
+		{Name: "old", URL: old.server.URL},
+		{Name: "good", URL: good.server.URL},
+	}, logger.Test(t), timeout, 0, RetryConfig{})
+
+	// the primary is verified before its first request and refused
+	height, err := pool.Client().LatestBlockHeight(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), height)
+	assert.Equal(t, "good", primary(pool))
+	states := pool.States()
+	assert.Equal(t, NodeStateUnsupportedVersion, states[0].State())
+	assert.Equal(t, "0.5.1", states[0].SpecVersion)
+	assert.Equal(t, MinSpecVersion, states[1].SpecVersion)
+
+	// refused nodes are not tried, even if every other node fails
+	requests := old.requests.Load()
+	good.down.Store(true)
+	_, err = pool.Client().LatestBlockHeight(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, requests, old.requests.Load())
+
+	// probes keep marking the node
+	pool.Probe(context.Background())
+	assert.ErrorIs(t, pool.States()[0].Err, ErrUnsupportedSpecVersion)
+}